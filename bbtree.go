@@ -340,10 +340,7 @@ func (tree *BBTree) ReindexQuery(f SpatialIndexQuery, data any) {
 	})
 
 	staticIndex := tree.spatialIndex.staticIndex
-	var staticRoot *Node
-	if staticIndex != nil {
-		staticRoot = staticIndex.class.(*BBTree).root
-	}
+	staticRoot := staticIndex.GetRootIfTree()
 
 	context := &MarkContext{tree, staticRoot, f, data}
 	tree.root.MarkSubtree(context)
@@ -0,0 +1,270 @@
+package cp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// BulkInsert inserts many objects at once by building a balanced subtree for them in a single pass.
+// This is much faster than calling Insert for each object, which makes it a good fit for loading large static levels.
+// Objects that are already in the tree are skipped.
+func (tree *BBTree) BulkInsert(objs []*Shape) {
+	leaves := make([]*Node, 0, len(objs))
+	for _, obj := range objs {
+		if tree.Contains(obj, obj.hashid) {
+			continue
+		}
+		leaves = append(leaves, tree.leaves.Insert(obj.hashid, obj, tree.NewLeaf))
+	}
+
+	tree.insertSubtree(tree.buildSubtree(leaves), leaves)
+}
+
+// buildSubtree recursively splits the leaves at the median of their centers along the longest axis.
+func (tree *BBTree) buildSubtree(leaves []*Node) *Node {
+	switch len(leaves) {
+	case 0:
+		return nil
+	case 1:
+		return leaves[0]
+	}
+
+	bounds := BB{INFINITY, INFINITY, -INFINITY, -INFINITY}
+	for _, leaf := range leaves {
+		bounds = bounds.Expand(leaf.bb.Center())
+	}
+
+	if bounds.R-bounds.L > bounds.T-bounds.B {
+		slices.SortFunc(leaves, func(a, b *Node) int {
+			return compareFloat(a.bb.L+a.bb.R, b.bb.L+b.bb.R)
+		})
+	} else {
+		slices.SortFunc(leaves, func(a, b *Node) int {
+			return compareFloat(a.bb.B+a.bb.T, b.bb.B+b.bb.T)
+		})
+	}
+
+	mid := len(leaves) / 2
+	return tree.NewNode(tree.buildSubtree(leaves[:mid]), tree.buildSubtree(leaves[mid:]))
+}
+
+func compareFloat(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// insertSubtree merges a subtree of new leaves into the tree and finds their pairs.
+func (tree *BBTree) insertSubtree(subtree *Node, leaves []*Node) {
+	if subtree == nil {
+		return
+	}
+
+	if tree.root == nil {
+		tree.root = subtree
+	} else {
+		tree.root = tree.NewNode(tree.root, subtree)
+	}
+
+	stamp := tree.GetMasterTree().stamp
+	for _, leaf := range leaves {
+		leaf.stamp = stamp
+		tree.LeafAddPairs(leaf)
+	}
+	tree.IncrementStamp()
+}
+
+const (
+	bbTreeBakeMagic   = 0x42427063 // "cpBB"
+	bbTreeBakeVersion = 1
+
+	bbTreeBakeBranch = -1
+	// number of nodes read at a time
+	bbTreeBakeChunk = 4096
+)
+
+// BBTreeBake is the saved layout of a BBTree.
+//
+// Leaves refer to objects by their index in the slice that was baked, so the tree can be restored later
+// for the same objects without rebuilding it.
+type BBTreeBake struct {
+	count int
+	// pre-order list of nodes, a leaf is the index of its object and a branch is bbTreeBakeBranch
+	nodes []int32
+}
+
+// Count returns the number of objects in the bake.
+func (bake *BBTreeBake) Count() int {
+	return bake.count
+}
+
+// Bake saves the layout of the tree. objs must hold every object in the tree, in the order they will be restored in.
+func (tree *BBTree) Bake(objs []*Shape) (*BBTreeBake, error) {
+	if len(objs) != tree.Count() {
+		return nil, fmt.Errorf("cp: baking %d objects but the tree holds %d", len(objs), tree.Count())
+	}
+
+	indexes := make(map[*Shape]int32, len(objs))
+	for i, obj := range objs {
+		indexes[obj] = int32(i)
+	}
+
+	bake := &BBTreeBake{count: len(objs)}
+	var bakeNode func(node *Node) error
+	bakeNode = func(node *Node) error {
+		if node.IsLeaf() {
+			index, ok := indexes[node.obj]
+			if !ok {
+				return errors.New("cp: the tree holds an object that is not being baked")
+			}
+			bake.nodes = append(bake.nodes, index)
+			return nil
+		}
+
+		bake.nodes = append(bake.nodes, bbTreeBakeBranch)
+		if err := bakeNode(node.a); err != nil {
+			return err
+		}
+		return bakeNode(node.b)
+	}
+
+	if tree.root != nil {
+		if err := bakeNode(tree.root); err != nil {
+			return nil, err
+		}
+	}
+	return bake, nil
+}
+
+// WriteTo writes the bake in a binary format that can be read back with ReadBBTreeBake.
+func (bake *BBTreeBake) WriteTo(w io.Writer) (int64, error) {
+	header := [3]uint32{bbTreeBakeMagic, bbTreeBakeVersion, uint32(bake.count)}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return 0, err
+	}
+	written := int64(binary.Size(header))
+
+	if err := binary.Write(w, binary.LittleEndian, bake.nodes); err != nil {
+		return written, err
+	}
+	return written + int64(binary.Size(bake.nodes)), nil
+}
+
+// ReadBBTreeBake reads a bake written by BBTreeBake.WriteTo and checks that it describes a valid tree.
+func ReadBBTreeBake(r io.Reader) (*BBTreeBake, error) {
+	var header [3]uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header[0] != bbTreeBakeMagic {
+		return nil, errors.New("cp: not a baked BBTree")
+	}
+	if header[1] != bbTreeBakeVersion {
+		return nil, fmt.Errorf("cp: unsupported baked BBTree version %d", header[1])
+	}
+
+	// leaves are stored as int32 indexes
+	if header[2] > math.MaxInt32 {
+		return nil, fmt.Errorf("cp: baked BBTree has too many objects %d", header[2])
+	}
+
+	bake := &BBTreeBake{count: int(header[2])}
+	if bake.count > 0 {
+		// a binary tree with n leaves has n-1 branches, read them a chunk at a time so a
+		// corrupt count can't allocate more than the data actually holds
+		remaining := 2*int64(bake.count) - 1
+		chunk := make([]int32, min(remaining, bbTreeBakeChunk))
+		for remaining > 0 {
+			chunk = chunk[:min(remaining, bbTreeBakeChunk)]
+			if err := binary.Read(r, binary.LittleEndian, chunk); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil, errors.New("cp: baked BBTree is truncated")
+				}
+				return nil, err
+			}
+			bake.nodes = append(bake.nodes, chunk...)
+			remaining -= int64(len(chunk))
+		}
+	}
+
+	if err := bake.validate(); err != nil {
+		return nil, err
+	}
+	return bake, nil
+}
+
+func (bake *BBTreeBake) validate() error {
+	used := make([]bool, bake.count)
+	next := 0
+
+	var validateNode func() error
+	validateNode = func() error {
+		if next >= len(bake.nodes) {
+			return errors.New("cp: baked BBTree is truncated")
+		}
+		index := bake.nodes[next]
+		next++
+
+		if index == bbTreeBakeBranch {
+			if err := validateNode(); err != nil {
+				return err
+			}
+			return validateNode()
+		}
+		if index < 0 || int(index) >= bake.count || used[index] {
+			return fmt.Errorf("cp: baked BBTree has an invalid leaf %d", index)
+		}
+		used[index] = true
+		return nil
+	}
+
+	if len(bake.nodes) == 0 {
+		return nil
+	}
+	if err := validateNode(); err != nil {
+		return err
+	}
+	if next != len(bake.nodes) {
+		return errors.New("cp: baked BBTree has trailing nodes")
+	}
+	return nil
+}
+
+// InsertBake inserts objs into the tree using a baked layout instead of building it.
+// objs must be in the same order as when the bake was made.
+func (tree *BBTree) InsertBake(bake *BBTreeBake, objs []*Shape) {
+	assert(len(objs) == bake.count, "The number of objects does not match the baked BBTree")
+
+	leaves := make([]*Node, 0, len(objs))
+	next := 0
+
+	var insertNode func() *Node
+	insertNode = func() *Node {
+		index := bake.nodes[next]
+		next++
+
+		if index == bbTreeBakeBranch {
+			a := insertNode()
+			b := insertNode()
+			return tree.NewNode(a, b)
+		}
+
+		obj := objs[index]
+		assert(!tree.Contains(obj, obj.hashid), "Object is already in the BBTree")
+		leaf := tree.leaves.Insert(obj.hashid, obj, tree.NewLeaf)
+		leaves = append(leaves, leaf)
+		return leaf
+	}
+
+	if len(bake.nodes) > 0 {
+		tree.insertSubtree(insertNode(), leaves)
+	}
+}
@@ -1,6 +1,9 @@
 package cp
 
 import (
+	"bytes"
+	"encoding/binary"
+	"math"
 	"testing"
 )
 
@@ -29,3 +32,61 @@ func TestBBTree_GetMasterTree(t *testing.T) {
 	}
 
 }
+
+func TestBBTree_Bake(t *testing.T) {
+	level := func(body *Body) []*Shape {
+		var shapes []*Shape
+		for i := range 1000 {
+			x := float64(i) * 10
+			shapes = append(shapes, NewSegment(body, Vector{x, 0}, Vector{x + 10, float64(i % 7)}, 1))
+		}
+		return shapes
+	}
+
+	space := NewSpace()
+	shapes := level(space.StaticBody)
+	space.AddShapes(shapes...)
+
+	bake, err := space.BakeStaticShapes(shapes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := bake.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadBBTreeBake(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewSpace()
+	shapes = level(reloaded.StaticBody)
+	reloaded.AddBakedShapes(loaded, shapes...)
+
+	if got := reloaded.staticShapes.class.Count(); got != len(shapes) {
+		t.Fatalf("Expected %v static shapes, got %v", len(shapes), got)
+	}
+
+	body := reloaded.AddBody(NewBody(1, MomentForCircle(1, 0, 2, Vector{})))
+	body.SetPosition(Vector{5005, 10})
+	reloaded.AddShape(NewCircle(body, 2, Vector{}))
+	reloaded.SetGravity(Vector{0, -100})
+	for range 120 {
+		reloaded.Step(1.0 / 60.0)
+	}
+	if body.Position().Y < 0 {
+		t.Errorf("Circle fell through the baked level: %v", body.Position())
+	}
+
+	if _, err := ReadBBTreeBake(bytes.NewReader([]byte("not a bake"))); err == nil {
+		t.Error("Expected an error reading garbage")
+	}
+
+	// a corrupt count must not allocate for nodes that aren't there
+	var corrupt bytes.Buffer
+	binary.Write(&corrupt, binary.LittleEndian, [3]uint32{bbTreeBakeMagic, bbTreeBakeVersion, math.MaxInt32})
+	if _, err := ReadBBTreeBake(&corrupt); err == nil {
+		t.Error("Expected an error reading a truncated bake")
+	}
+}
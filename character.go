@@ -0,0 +1,214 @@
+package cp
+
+import "math"
+
+// CharacterController moves an upright capsule through a space by sweeping it with shape casts,
+// instead of simulating it as a dynamic body. Up is along the positive y axis.
+//
+// The capsule isn't added to the space, so dynamic bodies only feel the character when it walks into them.
+type CharacterController struct {
+	// Position is the center of the capsule, which is Height tall including its rounded ends.
+	Position       Vector
+	Radius, Height float64
+
+	// MaxSlope is the steepest angle from horizontal that the character can walk up, in radians.
+	MaxSlope float64
+	// StepHeight is the height of the tallest ledge the character steps up onto without jumping.
+	StepHeight float64
+	// SkinWidth is the gap the character keeps from the surfaces it touches. It must be larger than the tolerance of GeometryCast.
+	SkinWidth float64
+	// Mass is used to push dynamic bodies out of the way.
+	Mass   float64
+	Filter ShapeFilter
+
+	// The ground the character was standing on at the end of the last move.
+	OnGround     bool
+	GroundNormal Vector
+	GroundBody   *Body
+	// GroundVelocity is the velocity of the ground under the character, which it is carried along with.
+	GroundVelocity Vector
+
+	space *Space
+}
+
+// NewCharacterController creates a character controller in space that can walk up 45 degree slopes and steps of half its radius.
+func NewCharacterController(space *Space, position Vector, radius, height float64) *CharacterController {
+	assert(radius > 0 && height >= 2*radius, "The capsule must be at least as tall as it is wide")
+	return &CharacterController{
+		Position:   position,
+		Radius:     radius,
+		Height:     height,
+		MaxSlope:   math.Pi / 4,
+		StepHeight: radius / 2,
+		SkinWidth:  10 * castTolerance,
+		Mass:       1,
+		Filter:     SHAPE_FILTER_ALL,
+		space:      space,
+	}
+}
+
+// Geometry returns the capsule of the character at position.
+func (character *CharacterController) Geometry(position Vector) *QueryGeometry {
+	half := Vector{0, character.Height/2 - character.Radius}
+	return NewCapsuleGeometry(position.Sub(half), position.Add(half), character.Radius)
+}
+
+// Move moves the character at velocity for a step of dt, sliding along anything it runs into and carrying it along with the ground it stands on.
+// It returns velocity without the parts that went into the surfaces it hit, for example with the fall stopped once it lands.
+func (character *CharacterController) Move(velocity Vector, dt float64) Vector {
+	character.depenetrate()
+
+	wasOnGround := character.OnGround
+	if wasOnGround {
+		// ride along with moving platforms
+		carried := character.GroundVelocity
+		character.slide(carried.Mult(dt), &carried, false)
+	}
+	character.slide(velocity.Mult(dt), &velocity, wasOnGround)
+
+	// keep to the ground when walking down slopes and steps, unless jumping off it
+	character.updateGround(wasOnGround && velocity.Y <= 0)
+	return velocity
+}
+
+func (character *CharacterController) walkable(normal Vector) bool {
+	return normal.Y > 0 && normal.Y >= math.Cos(character.MaxSlope)-1e-9
+}
+
+// slide moves the character along translation, sliding along the surfaces it hits and removing the parts of velocity going into them.
+func (character *CharacterController) slide(translation Vector, velocity *Vector, stepUp bool) {
+	remaining := translation
+	for range 4 {
+		if remaining.LengthSq() < 1e-12 {
+			return
+		}
+
+		position, hit := character.sweep(character.Position, remaining)
+		remaining = remaining.Sub(position.Sub(character.Position))
+		character.Position = position
+		if hit.Shape == nil {
+			return
+		}
+		character.push(&hit, *velocity)
+
+		n := hit.Normal
+		if character.walkable(n) {
+			// the ground holds the character up instead of sliding it downhill, so carry on horizontally along it
+			remaining = Vector{n.Y, -n.X}.Mult(remaining.X / n.Y)
+			velocity.Y = max(velocity.Y, 0)
+			continue
+		}
+
+		if stepUp && character.step(remaining) {
+			return
+		}
+		if n.Y > 0 && character.OnGround {
+			// treat slopes that are too steep like walls instead of sliding up them
+			n = Vector{n.X, 0}.Normalize()
+		}
+		remaining = remaining.Sub(n.Mult(remaining.Dot(n)))
+		*velocity = velocity.Sub(n.Mult(min(velocity.Dot(n), 0)))
+	}
+}
+
+// sweep moves the capsule from position along translation until it hits something, keeping SkinWidth away from it.
+func (character *CharacterController) sweep(position, translation Vector) (Vector, GeometryCastInfo) {
+	hit := GeometryCastInfo{Alpha: 1}
+	character.space.GeometryCast(character.Geometry(position), translation, character.Filter, func(info *GeometryCastInfo) {
+		// ignore what's being moved away from or along, so the character doesn't get stuck on what it's touching
+		if info.Shape.sensor || info.Normal.Dot(translation) >= 0 {
+			return
+		}
+		if hit.Shape == nil || info.Alpha < hit.Alpha {
+			hit = *info
+		}
+	})
+	if hit.Shape == nil {
+		return position.Add(translation), hit
+	}
+
+	length := translation.Length()
+	travel := max(hit.Alpha*length-character.SkinWidth, 0)
+	return position.Add(translation.Mult(travel / length)), hit
+}
+
+// step tries to move the character horizontally up onto a ledge no higher than StepHeight.
+func (character *CharacterController) step(remaining Vector) bool {
+	across := Vector{remaining.X, 0}
+	if character.StepHeight <= 0 || across.LengthSq() < 1e-12 {
+		return false
+	}
+
+	up, _ := character.sweep(character.Position, Vector{0, character.StepHeight})
+	over, _ := character.sweep(up, across)
+	if math.Abs(over.X-up.X) < 1e-6 {
+		return false
+	}
+	down, hit := character.sweep(over, Vector{0, character.Position.Y - up.Y - character.SkinWidth})
+	if hit.Shape == nil || !character.walkable(hit.Normal) {
+		return false
+	}
+
+	character.Position = down
+	return true
+}
+
+// push applies an impulse to a dynamic body the character runs into to stop it moving towards the character.
+func (character *CharacterController) push(hit *GeometryCastInfo, velocity Vector) {
+	body := hit.Shape.body
+	if body.GetType() != BODY_DYNAMIC {
+		return
+	}
+
+	point := hit.Points.Points[0].PointB
+	speed := velocity.Sub(body.VelocityAtWorldPoint(point)).Dot(hit.Normal.Neg())
+	if speed > 0 {
+		j := speed / (1/character.Mass + body.m_inv)
+		body.ApplyImpulseAtWorldPoint(hit.Normal.Mult(-j), point)
+	}
+}
+
+// depenetrate moves the character out of anything that moved into it since the last move.
+func (character *CharacterController) depenetrate() {
+	for range 4 {
+		var push Vector
+		character.space.GeometryQuery(character.Geometry(character.Position), character.Filter, func(shape *Shape, points *ContactPointSet) {
+			if shape.sensor {
+				return
+			}
+			depth := 0.0
+			for i := range points.Count {
+				depth = max(depth, -points.Points[i].Distance)
+			}
+			push = push.Sub(points.Normal.Mult(depth))
+		})
+		if push.LengthSq() == 0 {
+			return
+		}
+		character.Position = character.Position.Add(push)
+	}
+}
+
+// updateGround looks for walkable ground just below the character, or within StepHeight when snapping down onto it.
+func (character *CharacterController) updateGround(snap bool) {
+	probe := 2 * character.SkinWidth
+	if snap {
+		probe += character.StepHeight
+	}
+
+	position, hit := character.sweep(character.Position, Vector{0, -probe})
+	character.OnGround = hit.Shape != nil && character.walkable(hit.Normal)
+	if !character.OnGround {
+		character.GroundNormal = Vector{}
+		character.GroundBody = nil
+		character.GroundVelocity = Vector{}
+		return
+	}
+
+	if snap {
+		character.Position = position
+	}
+	character.GroundNormal = hit.Normal
+	character.GroundBody = hit.Shape.body
+	character.GroundVelocity = hit.Shape.body.VelocityAtWorldPoint(hit.Points.Points[0].PointB)
+}
@@ -0,0 +1,96 @@
+package cp
+
+import (
+	"math"
+	"testing"
+)
+
+// walk moves the character for the given number of 60Hz steps with gravity, starting at the given horizontal speed.
+func walk(space *Space, character *CharacterController, velocity Vector, steps int) Vector {
+	for range steps {
+		space.Step(1.0 / 60.0)
+		velocity.Y -= 100.0 / 60.0
+		velocity = character.Move(velocity, 1.0/60.0)
+	}
+	return velocity
+}
+
+func TestCharacterController(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	space.AddShape(NewSegment(space.StaticBody, Vector{-100, 0}, Vector{100, 0}, 0))
+	// a step, then a wall that's too tall to step onto
+	space.AddShape(NewBox2(space.StaticBody, BB{10, 0, 20, 0.2}, 0))
+	space.AddShape(NewBox2(space.StaticBody, BB{20, 0, 21, 5}, 0))
+	// ramps that are shallow enough to walk up and too steep to
+	space.AddShape(NewSegment(space.StaticBody, Vector{-10, 0}, Vector{-20, 10 * math.Tan(math.Pi/6)}, 0))
+	space.AddShape(NewSegment(space.StaticBody, Vector{-30, 0}, Vector{-40, 10 * math.Tan(math.Pi/3)}, 0))
+
+	character := NewCharacterController(space, Vector{0, 5}, 0.5, 2)
+
+	// falls to the ground and stops
+	velocity := walk(space, character, Vector{}, 60)
+	if !character.OnGround || character.GroundBody != space.StaticBody || !character.GroundNormal.Near(Vector{0, 1}, 1e-6) {
+		t.Fatalf("character didn't land: %+v", character)
+	}
+	if velocity != (Vector{}) || math.Abs(character.Position.Y-1) > 2*character.SkinWidth {
+		t.Errorf("character landed at %v with velocity %v", character.Position, velocity)
+	}
+
+	// walks up onto the step and stops at the wall
+	velocity = walk(space, character, Vector{10, 0}, 180)
+	if got := character.Position; math.Abs(got.Y-1.2) > 2*character.SkinWidth || math.Abs(got.X-19.5) > 2*character.SkinWidth {
+		t.Errorf("character walked to %v", got)
+	}
+	if math.Abs(velocity.X) > 1e-9 {
+		t.Errorf("character still moving into the wall at %v", velocity)
+	}
+
+	// walks up the shallow ramp
+	character.Position = Vector{-5, 1}
+	walk(space, character, Vector{-5, 0}, 150)
+	if got := character.Position; got.Y < 4 || !character.OnGround {
+		t.Errorf("character didn't walk up the shallow ramp, got to %v", got)
+	}
+
+	// but not the steep one
+	character.Position = Vector{-25, 1}
+	walk(space, character, Vector{-5, 0}, 120)
+	if got := character.Position; got.Y > 2 || !character.OnGround {
+		t.Errorf("character climbed the steep ramp up to %v", got)
+	}
+}
+
+func TestCharacterController_Platform(t *testing.T) {
+	space := NewSpace()
+	platform := space.AddBody(NewKinematicBody())
+	space.AddShape(NewBox2(platform, BB{-5, -1, 5, 0}, 0))
+	platform.SetVelocity(2, 1)
+
+	character := NewCharacterController(space, Vector{0, 1 + 0.005}, 0.5, 2)
+	walk(space, character, Vector{}, 60)
+	if !character.OnGround || character.GroundBody != platform || !character.GroundVelocity.Near(Vector{2, 1}, 1e-6) {
+		t.Fatalf("character isn't standing on the platform: %+v", character)
+	}
+	if got := character.Position; !got.Near(Vector{2, 2}, 0.1) {
+		t.Errorf("character wasn't carried along with the platform, got to %v", got)
+	}
+}
+
+func TestCharacterController_Push(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	space.AddShape(NewSegment(space.StaticBody, Vector{-100, 0}, Vector{100, 0}, 0))
+
+	crate := space.AddBody(NewBody(1, MomentForBox(1, 1, 1)))
+	crate.SetPosition(Vector{3, 0.5})
+	space.AddShape(NewBox(crate, 1, 1, 0))
+
+	character := NewCharacterController(space, Vector{0, 1.005}, 0.5, 2)
+	character.Mass = 10
+	walk(space, character, Vector{5, 0}, 60)
+
+	if crate.Position().X < 4 || character.Position.X > crate.Position().X-0.9 {
+		t.Errorf("character at %v didn't push the crate, which is at %v", character.Position, crate.Position())
+	}
+}
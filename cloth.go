@@ -0,0 +1,169 @@
+package cp
+
+// ClothOptions describes the particles and links of a cloth.
+type ClothOptions struct {
+	ParticleRadius, ParticleMass float64
+
+	// Springy joins the particles with damped springs using Stiffness and Damping.
+	// Otherwise they are joined with slide joints, which can go slack but don't stretch.
+	Springy            bool
+	Stiffness, Damping float64
+
+	// TearImpulse tears links that apply a larger impulse than it in a step. Zero makes the cloth untearable.
+	// Set a ConstraintBreakFunc on the space to find out when a link tears.
+	TearImpulse float64
+
+	// Filter is used for the particles' shapes, with the defaults described on ShapeFilter.
+	Filter *ShapeFilter
+}
+
+// Cloth is a grid of small circular particles, each linked to its neighbours along the rows and columns.
+type Cloth struct {
+	Columns, Rows int
+	// Bodies and Shapes hold the particles a row at a time, starting from the bottom left.
+	Bodies []*Body
+	Shapes []*Shape
+	Pins   []*Constraint
+
+	space *Space
+	links []clothLink
+}
+
+type clothLink struct {
+	a, b       int
+	constraint *Constraint
+}
+
+// NewCloth creates a cloth in space of columns by rows particles filling bb.
+func NewCloth(space *Space, bb BB, columns, rows int, opts ClothOptions) *Cloth {
+	assert(columns >= 2 && rows >= 2, "Cloth needs at least two columns and rows")
+	assert(opts.ParticleRadius > 0 && opts.ParticleMass > 0, "Particles must have a size and mass")
+
+	cloth := &Cloth{
+		Columns: columns,
+		Rows:    rows,
+		space:   space,
+	}
+
+	filter := compositeFilter(opts.Filter)
+
+	moment := MomentForCircle(opts.ParticleMass, 0, opts.ParticleRadius, Vector{})
+	for y := range rows {
+		for x := range columns {
+			body := space.AddBody(NewBody(opts.ParticleMass, moment))
+			body.SetPosition(Vector{
+				Lerp(bb.L, bb.R, float64(x)/float64(columns-1)),
+				Lerp(bb.B, bb.T, float64(y)/float64(rows-1)),
+			})
+			shape := space.AddShape(NewCircle(body, opts.ParticleRadius, Vector{}))
+			shape.SetFilter(filter)
+
+			cloth.Bodies = append(cloth.Bodies, body)
+			cloth.Shapes = append(cloth.Shapes, shape)
+		}
+	}
+
+	link := func(i, j int) {
+		a, b := cloth.Bodies[i], cloth.Bodies[j]
+		dist := a.Position().Distance(b.Position())
+
+		var constraint *Constraint
+		if opts.Springy {
+			constraint = NewDampedSpring(a, b, Vector{}, Vector{}, dist, opts.Stiffness, opts.Damping)
+		} else {
+			constraint = NewSlideJoint(a, b, Vector{}, Vector{}, 0, dist)
+		}
+		if opts.TearImpulse > 0 {
+			constraint.SetBreakImpulse(opts.TearImpulse)
+		}
+		constraint.SetCollideBodies(false)
+		cloth.links = append(cloth.links, clothLink{i, j, space.AddConstraint(constraint)})
+	}
+	for y := range rows {
+		for x := range columns {
+			if x+1 < columns {
+				link(cloth.index(x, y), cloth.index(x+1, y))
+			}
+			if y+1 < rows {
+				link(cloth.index(x, y), cloth.index(x, y+1))
+			}
+		}
+	}
+
+	return cloth
+}
+
+func (cloth *Cloth) index(x, y int) int {
+	return y*cloth.Columns + x
+}
+
+// Particle returns the body of the particle in column x and row y.
+func (cloth *Cloth) Particle(x, y int) *Body {
+	return cloth.Bodies[cloth.index(x, y)]
+}
+
+// Pin holds the particle in column x and row y at a point in the world. Pins never tear.
+func (cloth *Cloth) Pin(x, y int, point Vector) *Constraint {
+	pin := cloth.space.AddConstraint(NewPivotJoint2(cloth.space.StaticBody, cloth.Particle(x, y), point, Vector{}))
+	cloth.Pins = append(cloth.Pins, pin)
+	return pin
+}
+
+// Points returns the positions of the particles in the same order as Bodies.
+func (cloth *Cloth) Points() []Vector {
+	points := make([]Vector, len(cloth.Bodies))
+	for i, body := range cloth.Bodies {
+		points[i] = body.Position()
+	}
+	return points
+}
+
+// Links returns the pairs of indexes of the particles that are still linked together.
+func (cloth *Cloth) Links() [][2]int {
+	var links [][2]int
+	for _, link := range cloth.links {
+		if link.constraint.space != nil {
+			links = append(links, [2]int{link.a, link.b})
+		}
+	}
+	return links
+}
+
+// Triangles returns the indexes of the particles making up triangles to fill the cloth with.
+// Each square between four particles is split into two triangles, and is left out once any of its sides tear.
+func (cloth *Cloth) Triangles() [][3]int {
+	intact := map[[2]int]bool{}
+	for _, link := range cloth.Links() {
+		intact[link] = true
+	}
+
+	var triangles [][3]int
+	for y := 0; y < cloth.Rows-1; y++ {
+		for x := 0; x < cloth.Columns-1; x++ {
+			bl, br := cloth.index(x, y), cloth.index(x+1, y)
+			tl, tr := cloth.index(x, y+1), cloth.index(x+1, y+1)
+			if intact[[2]int{bl, br}] && intact[[2]int{tl, tr}] && intact[[2]int{bl, tl}] && intact[[2]int{br, tr}] {
+				triangles = append(triangles, [3]int{bl, br, tr}, [3]int{bl, tr, tl})
+			}
+		}
+	}
+	return triangles
+}
+
+// Remove removes the cloth's links, pins, shapes and bodies from the space.
+func (cloth *Cloth) Remove() {
+	for _, link := range cloth.links {
+		if link.constraint.space == cloth.space {
+			cloth.space.RemoveConstraint(link.constraint)
+		}
+	}
+	for _, pin := range cloth.Pins {
+		if pin.space == cloth.space {
+			cloth.space.RemoveConstraint(pin)
+		}
+	}
+	for i, body := range cloth.Bodies {
+		cloth.space.RemoveShape(cloth.Shapes[i])
+		cloth.space.RemoveBody(body)
+	}
+}
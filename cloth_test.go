@@ -0,0 +1,61 @@
+package cp
+
+import "testing"
+
+func TestCloth(t *testing.T) {
+	for _, springy := range []bool{false, true} {
+		space := NewSpace()
+		space.SetGravity(Vector{0, -100})
+		torn := 0
+		space.SetConstraintBreakFunc(func(constraint *Constraint, space *Space, impulse float64) {
+			torn++
+		})
+
+		cloth := NewCloth(space, BB{0, 0, 9, 9}, 10, 10, ClothOptions{
+			ParticleRadius: 0.2,
+			ParticleMass:   0.1,
+			Springy:        springy,
+			Stiffness:      100,
+			Damping:        1,
+			TearImpulse:    10,
+		})
+		for x := range cloth.Columns {
+			cloth.Pin(x, cloth.Rows-1, cloth.Particle(x, cloth.Rows-1).Position())
+		}
+		if links := len(cloth.Links()); links != 2*9*10 {
+			t.Fatalf("springy %v: got %v links", springy, links)
+		}
+		if triangles := len(cloth.Triangles()); triangles != 2*9*9 {
+			t.Fatalf("springy %v: got %v triangles", springy, triangles)
+		}
+
+		// hanging under its own weight doesn't tear it
+		stepSpace(space, 120)
+		if torn != 0 {
+			t.Errorf("springy %v: %v links tore under gravity", springy, torn)
+		}
+		if top := cloth.Particle(5, 9).Position(); !top.Near(Vector{5, 9}, 0.1) {
+			t.Errorf("springy %v: pinned particle moved to %v", springy, top)
+		}
+
+		// but yanking the bottom row does
+		for x := range cloth.Columns {
+			cloth.Particle(x, 0).SetVelocity(0, -1000)
+		}
+		stepSpace(space, 10)
+		if torn == 0 || len(cloth.Links()) != 2*9*10-torn {
+			t.Errorf("springy %v: %v links tore, %v are left", springy, torn, len(cloth.Links()))
+		}
+		if len(cloth.Triangles()) >= 2*9*9 {
+			t.Errorf("springy %v: torn cloth still has all of its triangles", springy)
+		}
+		if len(cloth.Points()) != 100 {
+			t.Errorf("springy %v: got %v points", springy, len(cloth.Points()))
+		}
+
+		cloth.Remove()
+		if len(space.dynamicBodies) != 0 || len(space.constraints) != 0 {
+			t.Errorf("springy %v: cloth wasn't removed from the space", springy)
+		}
+	}
+}
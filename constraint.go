@@ -9,9 +9,17 @@ type Constrainer interface {
 	GetImpulse() float64
 }
 
+// reactor is implemented by constraints that can report the impulses they applied to their bodies during the last step.
+type reactor interface {
+	reactionImpulses() Jacobian
+}
+
 type ConstraintPreSolveFunc func(*Constraint, *Space)
 type ConstraintPostSolveFunc func(*Constraint, *Space)
 
+// ConstraintBreakFunc is called after a breakable constraint has been removed from the space, with the impulse that broke it.
+type ConstraintBreakFunc func(constraint *Constraint, space *Space, impulse float64)
+
 type Constraint struct {
 	Class Constrainer
 	space *Space
@@ -21,6 +29,14 @@ type Constraint struct {
 
 	maxForce, errorBias, maxBias float64
 
+	frequency, dampingRatio float64
+	// how the constraint is solved during the current step
+	soft softness
+
+	breakImpulse, breakForce float64
+	// number of steps breakForce must be exceeded for, and how many steps in a row it has been
+	breakSteps, overloadedSteps int
+
 	collideBodies bool
 	PreSolve      ConstraintPreSolveFunc
 	PostSolve     ConstraintPostSolveFunc
@@ -39,6 +55,10 @@ func NewConstraint(class Constrainer, a, b *Body) *Constraint {
 		errorBias: math.Pow(1.0-0.1, 60.0),
 		maxBias:   INFINITY,
 
+		breakImpulse: INFINITY,
+		breakForce:   INFINITY,
+		breakSteps:   1,
+
 		collideBodies: true,
 		PreSolve:      nil,
 		PostSolve:     nil,
@@ -87,6 +107,68 @@ func (c *Constraint) SetErrorBias(errorBias float64) {
 	c.errorBias = errorBias
 }
 
+func (c Constraint) Softness() (frequency, dampingRatio float64) {
+	return c.frequency, c.dampingRatio
+}
+
+// SetSoftness makes the constraint behave like a damped spring with a natural frequency (in Hz) and damping ratio.
+// Unlike errorBias, this makes it equally springy regardless of the time step and masses of the bodies.
+// A frequency of 0 makes the constraint rigid again. Constraints that have no position to correct, like motors, ignore it.
+func (c *Constraint) SetSoftness(frequency, dampingRatio float64) {
+	assert(frequency >= 0 && dampingRatio >= 0, "Must be positive")
+	c.ActivateBodies()
+	c.frequency = frequency
+	c.dampingRatio = dampingRatio
+}
+
+// prepareSoftness sets up how the constraint is solved for a step of length dt.
+// Rigid constraints correct a fraction of their error each step according to errorBias.
+func (c *Constraint) prepareSoftness(dt float64) {
+	if c.frequency > 0 {
+		c.soft = newSoftness(c.frequency, c.dampingRatio, dt)
+	} else {
+		c.soft = softness{bias_coef(c.errorBias, dt) / dt, 1, 0}
+	}
+}
+
+func (c Constraint) BreakImpulse() float64 {
+	return c.breakImpulse
+}
+
+// SetBreakImpulse makes the space remove the constraint as soon as it applies an impulse larger than breakImpulse in a step.
+// The impulse is the one reported by GetImpulse, which includes the limits, motors and springs of a joint.
+func (c *Constraint) SetBreakImpulse(breakImpulse float64) {
+	assert(breakImpulse >= 0, "Must be positive")
+	c.breakImpulse = breakImpulse
+}
+
+func (c Constraint) BreakForce() (float64, int) {
+	return c.breakForce, c.breakSteps
+}
+
+// SetBreakForce makes the space remove the constraint once it applies a force larger than breakForce for steps steps in a row.
+func (c *Constraint) SetBreakForce(breakForce float64, steps int) {
+	assert(breakForce >= 0, "Must be positive")
+	assert(steps > 0, "Must be at least one step")
+	c.breakForce = breakForce
+	c.breakSteps = steps
+	c.overloadedSteps = 0
+}
+
+// checkBreak is called after the constraint has been solved and returns true if it should break.
+func (c *Constraint) checkBreak(impulse, dt float64) bool {
+	if impulse > c.breakImpulse {
+		return true
+	}
+
+	if c.breakForce < INFINITY && impulse > c.breakForce*dt {
+		c.overloadedSteps++
+	} else {
+		c.overloadedSteps = 0
+	}
+	return c.overloadedSteps >= c.breakSteps
+}
+
 func (c *Constraint) Next(body *Body) *Constraint {
 	if c.a == body {
 		return c.next_a
@@ -99,3 +181,91 @@ func (c *Constraint) SetCollideBodies(collideBodies bool) {
 	c.ActivateBodies()
 	c.collideBodies = collideBodies
 }
+
+// softness holds the coefficients used to solve a constraint as a damped spring with a natural frequency (in Hz) and damping ratio.
+// Unlike errorBias this behaves the same regardless of the masses of the bodies and the time step.
+type softness struct {
+	biasRate, massScale, impulseScale float64
+}
+
+// A rigid constraint uses a mass scale of 1 and an impulse scale of 0, and the bias rate derived from errorBias.
+// A constraint with an error of c, an effective mass and an accumulated impulse jAcc is solved with
+//
+//	j = massScale*mass*(-biasRate*c - v) - impulseScale*jAcc
+
+func newSoftness(frequency, dampingRatio, dt float64) softness {
+	if frequency <= 0 {
+		return softness{0, 1, 0}
+	}
+
+	omega := 2 * math.Pi * frequency
+	a1 := 2*dampingRatio + dt*omega
+	a2 := dt * omega * a1
+	a3 := 1 / (1 + a2)
+	return softness{omega / a1, a2 * a3, a3}
+}
+
+// bias returns the velocity bias to correct an error of c, limited to maxBias.
+func (s softness) bias(c, maxBias float64) float64 {
+	return Clamp(-s.biasRate*c, -maxBias, maxBias)
+}
+
+// biasVect returns the velocity bias to correct an error of c, limited to maxBias.
+func (s softness) biasVect(c Vector, maxBias float64) Vector {
+	return c.Mult(-s.biasRate).Clamp(maxBias)
+}
+
+// limitBias returns the velocity bias of a limit that is separated by c, limited to maxBias.
+func (s softness) limitBias(c, dt, maxBias float64) float64 {
+	if c > 0 {
+		// allow the limit to close the gap in one step, but no further
+		return -c / dt
+	}
+	return s.bias(c, maxBias)
+}
+
+// impulse returns the impulse to apply for a velocity error of dv along a constraint with the given mass and accumulated impulse.
+func (s softness) impulse(mass, dv, jAcc float64) float64 {
+	return s.massScale*mass*dv - s.impulseScale*jAcc
+}
+
+// impulseVect is impulse for two dimensional constraints with a mass tensor k.
+func (s softness) impulseVect(k *Mat2x2, dv, jAcc Vector) Vector {
+	return k.Transform(dv).Mult(s.massScale).Sub(jAcc.Mult(s.impulseScale))
+}
+
+// impulseToForce converts an impulse applied during the last step into a force.
+func (c *Constraint) impulseToForce(j float64) float64 {
+	if c.space == nil || c.space.curr_dt == 0 {
+		return 0
+	}
+	return j / c.space.curr_dt
+}
+
+// bodyReaction returns the linear and angular impulses applied to body during the last step.
+func (c *Constraint) bodyReaction(body *Body) (Vector, float64) {
+	assert(body == c.a || body == c.b, "Body is not attached to the constraint")
+
+	r, ok := c.Class.(reactor)
+	if !ok {
+		return Vector{}, 0
+	}
+	j := r.reactionImpulses()
+	if body == c.a {
+		return j.LinearA, j.AngularA
+	}
+	return j.LinearB, j.AngularB
+}
+
+// ReactionForce returns the force the constraint applied to body, one of its two bodies, during the last step.
+// Constraints that don't report their reactions, such as custom ones, return a zero vector.
+func (c *Constraint) ReactionForce(body *Body) Vector {
+	j, _ := c.bodyReaction(body)
+	return Vector{c.impulseToForce(j.X), c.impulseToForce(j.Y)}
+}
+
+// ReactionTorque returns the torque about its center of gravity that the constraint applied to body during the last step.
+func (c *Constraint) ReactionTorque(body *Body) float64 {
+	_, j := c.bodyReaction(body)
+	return c.impulseToForce(j)
+}
@@ -0,0 +1,667 @@
+package cp
+
+import (
+	"math"
+	"testing"
+)
+
+// stepSpace steps the space for the given number of 60Hz steps.
+func stepSpace(space *Space, steps int) {
+	for range steps {
+		space.Step(1.0 / 60.0)
+	}
+}
+
+func TestWeldJoint(t *testing.T) {
+	for _, frequency := range []float64{0, 30} {
+		space := NewSpace()
+		space.SetGravity(Vector{0, -100})
+
+		// a beam sticking out horizontally from a wall
+		beam := space.AddBody(NewBody(1, MomentForBox(1, 10, 1)))
+		beam.SetPosition(Vector{5, 0})
+		weld := space.AddConstraint(NewWeldJoint(space.StaticBody, beam, Vector{}))
+		weld.SetSoftness(frequency, 1)
+
+		stepSpace(space, 120)
+
+		if d := beam.Position().Distance(Vector{5, 0}); d > 0.5 {
+			t.Errorf("frequency %v: beam moved %v from the wall", frequency, d)
+		}
+		if math.Abs(beam.Angle()) > 0.1 {
+			t.Errorf("frequency %v: beam sagged to %v", frequency, beam.Angle())
+		}
+		if weld.Class.GetImpulse() == 0 {
+			t.Errorf("frequency %v: expected the weld to hold the beam up", frequency)
+		}
+	}
+
+	// with fixed rotation on both sides only the point can be solved
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	box := space.AddBody(NewBody(1, INFINITY))
+	box.SetPosition(Vector{5, 0})
+	space.AddConstraint(NewWeldJoint(space.StaticBody, box, Vector{}))
+
+	stepSpace(space, 120)
+
+	if d := box.Position().Distance(Vector{5, 0}); !(d < 0.5) {
+		t.Errorf("fixed rotation: box moved %v from the wall", d)
+	}
+}
+
+func TestWheelJoint(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	wheel := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	wheel.SetPosition(Vector{0, -1})
+	constraint := space.AddConstraint(NewWheelJoint(space.StaticBody, wheel, Vector{}, Vector{}, Vector{0, 1}))
+	joint := constraint.Class.(*WheelJoint)
+	joint.Frequency = 2
+	joint.DampingRatio = 1
+	joint.EnableMotor = true
+	joint.MotorSpeed = 5
+	joint.MaxMotorTorque = INFINITY
+
+	stepSpace(space, 300)
+
+	// the spring settles where it holds up the wheel: k*x = m*g
+	k := math.Pow(2*math.Pi*joint.Frequency, 2)
+	if got, want := joint.Translation(), -100/k; math.Abs(got-want) > 0.05 {
+		t.Errorf("suspension settled at %v, want %v", got, want)
+	}
+	if math.Abs(joint.Speed()) > 0.01 {
+		t.Errorf("suspension still moving at %v", joint.Speed())
+	}
+	if math.Abs(wheel.Position().X) > 0.01 {
+		t.Errorf("wheel left the axis: %v", wheel.Position())
+	}
+	if math.Abs(wheel.AngularVelocity()-5) > 0.01 {
+		t.Errorf("wheel spinning at %v, want 5", wheel.AngularVelocity())
+	}
+}
+
+func TestPrismaticJoint(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	// an elevator car driven up a shaft until it hits the top
+	car := space.AddBody(NewBody(1, MomentForBox(1, 2, 1)))
+	constraint := space.AddConstraint(NewPrismaticJoint(space.StaticBody, car, Vector{}, Vector{}, Vector{0, 1}))
+	joint := constraint.Class.(*PrismaticJoint)
+	joint.EnableLimit = true
+	joint.Lower = -1
+	joint.Upper = 3
+	joint.EnableMotor = true
+	joint.MotorSpeed = 5
+	joint.MaxMotorForce = 1000
+
+	// push the car sideways and spin it, which the joint should resist
+	car.SetVelocity(10, 0)
+	car.SetAngularVelocity(5)
+
+	stepSpace(space, 120)
+	if got := joint.Translation(); math.Abs(got-joint.Upper) > 0.01 {
+		t.Errorf("car stopped at %v, want the upper limit", got)
+	}
+	if math.Abs(car.Position().X) > 0.01 || math.Abs(car.Angle()) > 0.01 {
+		t.Errorf("car left the shaft: %v %v", car.Position(), car.Angle())
+	}
+
+	// without the motor it falls to the bottom
+	joint.EnableMotor = false
+	stepSpace(space, 120)
+	if got := joint.Translation(); math.Abs(got-joint.Lower) > 0.01 {
+		t.Errorf("car stopped at %v, want the lower limit", got)
+	}
+	if math.Abs(joint.Speed()) > 0.01 {
+		t.Errorf("car still moving at %v", joint.Speed())
+	}
+
+	// a car with fixed rotation only needs the perpendicular row
+	space = NewSpace()
+	space.SetGravity(Vector{0, -100})
+	car = space.AddBody(NewBody(1, INFINITY))
+	car.SetVelocity(10, 0)
+	space.AddConstraint(NewPrismaticJoint(space.StaticBody, car, Vector{}, Vector{}, Vector{0, 1}))
+
+	stepSpace(space, 60)
+	if got := car.Position(); !(math.Abs(got.X) < 0.01) || !(got.Y < -10) {
+		t.Errorf("fixed rotation car at %v, want it to fall down the shaft", got)
+	}
+	if got := car.AngularVelocity(); got != 0 {
+		t.Errorf("fixed rotation car spinning at %v", got)
+	}
+}
+
+func TestRevoluteJoint(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	// a horizontal arm hinged at the origin that swings down until it hits the limit
+	arm := space.AddBody(NewBody(1, MomentForBox(1, 4, 0.5)))
+	arm.SetPosition(Vector{2, 0})
+	constraint := space.AddConstraint(NewRevoluteJoint(space.StaticBody, arm, Vector{}))
+	joint := constraint.Class.(*RevoluteJoint)
+	joint.EnableLimit = true
+	joint.LowerAngle = -math.Pi / 4
+	joint.UpperAngle = math.Pi / 4
+
+	stepSpace(space, 120)
+	if got := joint.Angle(); math.Abs(got-joint.LowerAngle) > 0.01 {
+		t.Errorf("arm stopped at %v, want the lower limit", got)
+	}
+	if got := arm.LocalToWorld(Vector{-2, 0}).Length(); got > 0.01 {
+		t.Errorf("arm came off the hinge by %v", got)
+	}
+	// the joint holds up the weight of the arm without turning it
+	if got := joint.ReactionForce(arm); got.Distance(Vector{0, 100}) > 1 {
+		t.Errorf("unexpected reaction force %v", got)
+	}
+	if got := joint.ReactionTorque(arm); math.Abs(got) > 1 {
+		t.Errorf("unexpected reaction torque %v", got)
+	}
+	// and the limit pushes back on the wall
+	if got := joint.ReactionTorque(space.StaticBody); got > -1 {
+		t.Errorf("unexpected reaction torque on the wall %v", got)
+	}
+
+	// a motor strong enough to lift the arm up to the upper limit
+	joint.EnableMotor = true
+	joint.MotorSpeed = 2
+	joint.MaxMotorTorque = 1000
+	stepSpace(space, 120)
+	if got := joint.Angle(); math.Abs(got-joint.UpperAngle) > 0.01 {
+		t.Errorf("arm stopped at %v, want the upper limit", got)
+	}
+
+	// a spring holds it close to level
+	joint.EnableMotor = false
+	joint.EnableLimit = false
+	joint.Frequency = 10
+	joint.DampingRatio = 1
+	stepSpace(space, 120)
+	if got := joint.Angle(); got > 0 || got < -0.1 {
+		t.Errorf("spring held the arm at %v", got)
+	}
+
+	// limits can't turn a body with fixed rotation, but the hinge still holds it
+	space = NewSpace()
+	space.SetGravity(Vector{0, -100})
+	box := space.AddBody(NewBody(1, INFINITY))
+	box.SetPosition(Vector{2, 0})
+	joint = space.AddConstraint(NewRevoluteJoint(space.StaticBody, box, Vector{})).Class.(*RevoluteJoint)
+	joint.EnableLimit = true
+	joint.LowerAngle = 0.5
+	joint.UpperAngle = 1
+
+	stepSpace(space, 60)
+	if got := box.Position(); !(got.Distance(Vector{2, 0}) < 0.01) || !(math.Abs(box.AngularVelocity()) < 1e-6) {
+		t.Errorf("fixed rotation box moved to %v", got)
+	}
+}
+
+func TestTargetJoint(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	box := space.AddBody(NewBody(1, MomentForBox(1, 1, 1)))
+	space.AddShape(NewBox(box, 1, 1, 0))
+	constraint := space.AddConstraint(NewTargetJoint(box, Vector{}, Vector{10, 10}))
+	constraint.SetMaxForce(1000)
+	joint := constraint.Class.(*TargetJoint)
+
+	stepSpace(space, 180)
+	if got := box.Position(); got.Distance(joint.Target()) > 0.5 {
+		t.Errorf("box is at %v, want it near %v", got, joint.Target())
+	}
+
+	// too weak to lift the box
+	constraint.SetMaxForce(50)
+	joint.SetTarget(Vector{10, 20})
+	stepSpace(space, 60)
+	if got := box.Position(); got.Y > 10 {
+		t.Errorf("weak joint lifted the box to %v", got)
+	}
+
+	space.RemoveConstraint(constraint)
+}
+
+func TestMotorJoint(t *testing.T) {
+	space := NewSpace()
+
+	platform := space.AddBody(NewBody(1, MomentForBox(1, 4, 1)))
+	constraint := space.AddConstraint(NewMotorJoint(space.StaticBody, platform))
+	constraint.SetMaxForce(1000)
+	joint := constraint.Class.(*MotorJoint)
+	joint.MaxTorque = 1000
+	joint.LinearOffset = Vector{5, 2}
+	joint.AngularOffset = 1
+
+	stepSpace(space, 120)
+	if got := platform.Position(); got.Distance(joint.LinearOffset) > 0.01 {
+		t.Errorf("platform is at %v, want %v", got, joint.LinearOffset)
+	}
+	if got := platform.Angle(); math.Abs(got-joint.AngularOffset) > 0.01 {
+		t.Errorf("platform angle is %v, want %v", got, joint.AngularOffset)
+	}
+
+	// too weak to move it far in one step
+	constraint.SetMaxForce(1)
+	joint.LinearOffset = Vector{}
+	space.Step(1.0 / 60.0)
+	if got := platform.Position(); got.Distance(Vector{5, 2}) > 0.01 {
+		t.Errorf("weak motor moved the platform to %v", got)
+	}
+
+	// a soft motor eases towards the target instead of using the correction factor
+	constraint.SetMaxForce(1000)
+	constraint.SetSoftness(0.5, 1)
+	stepSpace(space, 6)
+	if got := platform.Position().Length(); got < 0.5*math.Sqrt(29) {
+		t.Errorf("soft motor moved the platform to %v too quickly", platform.Position())
+	}
+	stepSpace(space, 300)
+	if got := platform.Position(); got.Length() > 0.01 {
+		t.Errorf("soft motor left the platform at %v", got)
+	}
+}
+
+func TestPulleyJoint(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	// a heavy weight on the left lifts a light one on the right
+	heavy := space.AddBody(NewBody(2, INFINITY))
+	heavy.SetPosition(Vector{-5, 0})
+	light := space.AddBody(NewBody(1, INFINITY))
+	light.SetPosition(Vector{5, 0})
+	constraint := space.AddConstraint(NewPulleyJoint(heavy, light, Vector{-5, 10}, Vector{5, 10}, Vector{}, Vector{}, 1))
+	joint := constraint.Class.(*PulleyJoint)
+	joint.MaxLengthA = 15
+
+	stepSpace(space, 120)
+	if got := joint.LengthA(); math.Abs(got-joint.MaxLengthA) > 0.05 {
+		t.Errorf("heavy side stopped at %v, want %v", got, joint.MaxLengthA)
+	}
+	if got := joint.LengthA() + joint.LengthB(); math.Abs(got-joint.Length) > 0.05 {
+		t.Errorf("rope length is %v, want %v", got, joint.Length)
+	}
+
+	// throwing the light weight up pushes the heavy one down
+	joint.MaxLengthA = INFINITY
+	light.SetVelocity(0, 50)
+	space.Step(1.0 / 60.0)
+	if joint.GetImpulse() >= 0 {
+		t.Errorf("rope applied an impulse of %v, want it to push", joint.GetImpulse())
+	}
+
+	// unless the rope is allowed to go slack instead of pushing
+	joint.Slack = true
+	light.SetVelocity(0, 50)
+	space.Step(1.0 / 60.0)
+	if joint.GetImpulse() != 0 {
+		t.Errorf("slack rope applied an impulse of %v", joint.GetImpulse())
+	}
+}
+
+func TestFrictionJoint(t *testing.T) {
+	space := NewSpace()
+
+	puck := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	puck.SetVelocity(10, 0)
+	puck.SetAngularVelocity(10)
+	space.AddConstraint(NewFrictionJoint(space.StaticBody, puck, 60, 6))
+
+	// friction takes 10/60 seconds to stop the puck and 5/6 seconds to stop the spin
+	stepSpace(space, 5)
+	if got := puck.Velocity().X; math.Abs(got-5) > 0.01 {
+		t.Errorf("puck slowed to %v, want 5", got)
+	}
+	stepSpace(space, 55)
+	if got := puck.Velocity().Length(); got > 1e-6 {
+		t.Errorf("puck still moving at %v", got)
+	}
+	if got := puck.AngularVelocity(); got > 1e-6 {
+		t.Errorf("puck still spinning at %v", got)
+	}
+}
+
+func TestRackAndPinionJoint(t *testing.T) {
+	space := NewSpace()
+
+	// a rack on a horizontal slider driven by a motor turns a pinion on a hinge above it
+	rack := space.AddBody(NewBody(1, MomentForBox(1, 10, 1)))
+	slider := space.AddConstraint(NewPrismaticJoint(space.StaticBody, rack, Vector{}, Vector{}, Vector{1, 0})).Class.(*PrismaticJoint)
+	slider.EnableMotor = true
+	slider.MotorSpeed = 2
+	slider.MaxMotorForce = 1000
+
+	pinion := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	pinion.SetPosition(Vector{0, 1.5})
+	space.AddConstraint(NewRevoluteJoint(space.StaticBody, pinion, pinion.Position()))
+	space.AddConstraint(NewRackAndPinionJoint(pinion, rack, Vector{1, 0}, 1))
+
+	stepSpace(space, 60)
+	// the rack moving right under the pinion is the same as the pinion rolling left along the rack
+	if got, want := pinion.Angle(), slider.Translation(); math.Abs(got-want) > 0.01 {
+		t.Errorf("pinion turned %v, want %v", got, want)
+	}
+	if math.Abs(slider.Translation()-2) > 0.1 {
+		t.Errorf("rack moved %v, want 2", slider.Translation())
+	}
+}
+
+func TestCouplingJoint(t *testing.T) {
+	space := NewSpace()
+
+	// a motorized crank drives a slider at half the rate
+	crank := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	hinge := space.AddConstraint(NewRevoluteJoint(space.StaticBody, crank, Vector{}))
+	hinge.Class.(*RevoluteJoint).EnableMotor = true
+	hinge.Class.(*RevoluteJoint).MotorSpeed = 1
+	hinge.Class.(*RevoluteJoint).MaxMotorTorque = 1000
+
+	block := space.AddBody(NewBody(1, MomentForBox(1, 1, 1)))
+	block.SetPosition(Vector{0, 5})
+	slider := space.AddConstraint(NewPrismaticJoint(space.StaticBody, block, Vector{0, 5}, Vector{}, Vector{1, 0}))
+	space.AddConstraint(NewCouplingJoint(hinge, slider, 2))
+
+	stepSpace(space, 60)
+	angle := hinge.Class.(*RevoluteJoint).Angle()
+	translation := slider.Class.(*PrismaticJoint).Translation()
+	if math.Abs(angle+2*translation) > 0.01 {
+		t.Errorf("angle %v and translation %v aren't coupled", angle, translation)
+	}
+	if angle < 0.5 {
+		t.Errorf("crank only turned %v", angle)
+	}
+
+	// two gears on a floating plate, the coupling must push the plate too or it adds spin out of nothing
+	space = NewSpace()
+	plate := space.AddBody(NewBody(4, MomentForBox(4, 6, 1)))
+	gear1 := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	gear1.SetPosition(Vector{-2, 0})
+	gear1.SetAngularVelocity(1)
+	gear2 := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	gear2.SetPosition(Vector{2, 0})
+	hinge1 := space.AddConstraint(NewRevoluteJoint(plate, gear1, Vector{-2, 0}))
+	hinge2 := space.AddConstraint(NewRevoluteJoint(plate, gear2, Vector{2, 0}))
+	space.AddConstraint(NewCouplingJoint(hinge1, hinge2, 1))
+
+	angularMomentum := func() (l float64) {
+		for _, body := range []*Body{plate, gear1, gear2} {
+			l += body.Mass()*body.Position().Cross(body.Velocity()) + body.Moment()*body.AngularVelocity()
+		}
+		return l
+	}
+	before := angularMomentum()
+	stepSpace(space, 60)
+	if got := angularMomentum(); math.Abs(got-before) > 0.01 {
+		t.Errorf("angular momentum changed from %v to %v", before, got)
+	}
+	angle1 := hinge1.Class.(*RevoluteJoint).Angle()
+	angle2 := hinge2.Class.(*RevoluteJoint).Angle()
+	if math.Abs(angle1+angle2) > 0.01 {
+		t.Errorf("angles %v and %v aren't coupled", angle1, angle2)
+	}
+}
+
+func TestConstraint_BreakSpring(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	// a spring stretched by a hanging weight pulls, so it reports a negative impulse
+	weight := space.AddBody(NewBody(1, INFINITY))
+	weight.SetPosition(Vector{0, -2})
+	spring := space.AddConstraint(NewDampedSpring(space.StaticBody, weight, Vector{}, Vector{}, 1, 100, 20))
+	spring.SetBreakForce(150, 1)
+
+	broken := 0
+	space.SetConstraintBreakFunc(func(constraint *Constraint, space *Space, impulse float64) {
+		broken++
+	})
+
+	stepSpace(space, 60)
+	if broken != 0 {
+		t.Fatal("the spring broke while holding less than its break force")
+	}
+
+	space.SetGravity(Vector{0, -300})
+	stepSpace(space, 60)
+	if broken != 1 || space.ContainsConstraint(spring) {
+		t.Fatal("expected the stretched spring to break")
+	}
+}
+
+func TestConstraint_BreakLimit(t *testing.T) {
+	space := NewSpace()
+
+	// a wheel twisted against the limit of a hinge at its center only loads the limit
+	wheel := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	hinge := space.AddConstraint(NewRevoluteJoint(space.StaticBody, wheel, Vector{}))
+	joint := hinge.Class.(*RevoluteJoint)
+	joint.EnableLimit = true
+	joint.UpperAngle = 0.1
+	hinge.SetBreakForce(5000, 1)
+
+	broken := 0
+	space.SetConstraintBreakFunc(func(constraint *Constraint, space *Space, impulse float64) {
+		broken++
+	})
+
+	twist := func(torque float64) {
+		for range 30 {
+			wheel.SetTorque(torque)
+			space.Step(1.0 / 60.0)
+		}
+	}
+	twist(1000)
+	if broken != 0 {
+		t.Fatal("the hinge broke while holding less than its break force")
+	}
+	twist(10000)
+	if broken != 1 || space.ContainsConstraint(hinge) {
+		t.Fatal("expected the hinge to break at its limit")
+	}
+}
+
+func TestConstraint_Break(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	weight := space.AddBody(NewBody(1, INFINITY))
+	constraint := space.AddConstraint(NewPivotJoint(space.StaticBody, weight, Vector{}))
+	constraint.SetBreakForce(150, 10)
+
+	var broken []*Constraint
+	space.SetConstraintBreakFunc(func(constraint *Constraint, space *Space, impulse float64) {
+		broken = append(broken, constraint)
+		if force := impulse * 60; force < 150 {
+			t.Errorf("broke with a force of %v", force)
+		}
+	})
+
+	stepSpace(space, 60)
+	if len(broken) != 0 {
+		t.Fatal("the joint broke while holding less than its break force")
+	}
+
+	// overloaded for fewer steps than needed to break it
+	space.SetGravity(Vector{0, -200})
+	stepSpace(space, 5)
+	space.SetGravity(Vector{0, -100})
+	stepSpace(space, 10)
+	if len(broken) != 0 {
+		t.Fatal("the joint broke before being overloaded long enough")
+	}
+
+	space.SetGravity(Vector{0, -200})
+	stepSpace(space, 10)
+	if len(broken) != 1 || broken[0] != constraint || space.ContainsConstraint(constraint) {
+		t.Fatal("expected the joint to break and be removed")
+	}
+
+	// breaking on a single large impulse
+	constraint = space.AddConstraint(NewPivotJoint(space.StaticBody, weight, weight.Position()))
+	constraint.SetBreakImpulse(1)
+	weight.SetVelocity(0, -100)
+	space.Step(1.0 / 60.0)
+	if len(broken) != 2 || space.ContainsConstraint(constraint) {
+		t.Fatal("expected the joint to break on impact")
+	}
+}
+
+func TestConstraint_ReactionForce(t *testing.T) {
+	joints := map[string]func(ground, weight *Body) *Constraint{
+		"PinJoint": func(ground, weight *Body) *Constraint {
+			return NewPinJoint(ground, weight, Vector{0, 1}, Vector{})
+		},
+		"SlideJoint": func(ground, weight *Body) *Constraint {
+			return NewSlideJoint(ground, weight, Vector{0, 1}, Vector{}, 0, 1)
+		},
+		"PivotJoint": func(ground, weight *Body) *Constraint {
+			return NewPivotJoint(ground, weight, Vector{})
+		},
+		"DampedSpring": func(ground, weight *Body) *Constraint {
+			return NewDampedSpring(ground, weight, Vector{0, 1}, Vector{}, 0, 100, 20)
+		},
+		"WeldJoint": func(ground, weight *Body) *Constraint {
+			return NewWeldJoint(ground, weight, Vector{-1, 0})
+		},
+		"RevoluteJoint": func(ground, weight *Body) *Constraint {
+			return NewRevoluteJoint(ground, weight, Vector{})
+		},
+		"TargetJoint": func(ground, weight *Body) *Constraint {
+			return NewTargetJoint(weight, Vector{}, Vector{})
+		},
+		"MotorJoint": func(ground, weight *Body) *Constraint {
+			return NewMotorJoint(ground, weight)
+		},
+	}
+
+	for name, newJoint := range joints {
+		space := NewSpace()
+		space.SetGravity(Vector{0, -100})
+		weight := space.AddBody(NewBody(1, 1))
+		constraint := space.AddConstraint(newJoint(space.StaticBody, weight))
+
+		stepSpace(space, 300)
+		if got := constraint.ReactionForce(weight); got.Distance(Vector{0, 100}) > 1 {
+			t.Errorf("%v: reaction force %v, want it to hold up the weight", name, got)
+		}
+		if got := constraint.ReactionTorque(weight); math.Abs(got) > 1 {
+			t.Errorf("%v: reaction torque %v, want none", name, got)
+		}
+		if got := constraint.ReactionForce(constraint.A()); got.Distance(Vector{0, -100}) > 1 {
+			t.Errorf("%v: reaction force %v on the other body, want the opposite", name, got)
+		}
+	}
+}
+
+func TestConstraint_SetSoftness(t *testing.T) {
+	// a soft pin stretches until it holds up the weight like a spring: m*g = m*(2*pi*f)^2*x
+	const frequency = 2
+	want := 1 + 100/math.Pow(2*math.Pi*frequency, 2)
+
+	for _, dt := range []float64{1.0 / 30.0, 1.0 / 60.0, 1.0 / 240.0} {
+		space := NewSpace()
+		space.SetGravity(Vector{0, -100})
+		weight := space.AddBody(NewBody(1, INFINITY))
+		weight.SetPosition(Vector{0, -1})
+		pin := space.AddConstraint(NewPinJoint(space.StaticBody, weight, Vector{}, Vector{}))
+		pin.SetSoftness(frequency, 1)
+
+		for range int(5 / dt) {
+			space.Step(dt)
+		}
+		if got := -weight.Position().Y; math.Abs(got-want) > 0.01 {
+			t.Errorf("dt %v: weight hangs at %v, want %v", dt, got, want)
+		}
+	}
+}
+
+func TestConstraint1D(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	// a rope of length 2, written as a custom constraint
+	weight := space.AddBody(NewBody(1, 1))
+	weight.SetPosition(Vector{0, -1})
+	rope := NewConstraint1D(space.StaticBody, weight, func(rope *Constraint1D, dt float64) (Jacobian, float64) {
+		a, b := rope.A(), rope.B()
+		r1 := AnchorOffset(a, Vector{})
+		r2 := AnchorOffset(b, Vector{})
+		delta := b.LocalToWorld(Vector{}).Sub(a.LocalToWorld(Vector{}))
+		return NewPointJacobian(r1, r2, delta.Normalize().Neg()), 2 - delta.Length()
+	})
+	rope.Class.(*Constraint1D).Unilateral = true
+	space.AddConstraint(rope)
+
+	// slack at first, so it falls freely
+	space.Step(1.0 / 60.0)
+	if rope.Class.GetImpulse() != 0 {
+		t.Errorf("slack rope applied an impulse of %v", rope.Class.GetImpulse())
+	}
+
+	stepSpace(space, 600)
+	if got := weight.Position(); got.Distance(Vector{0, -2}) > 0.05 {
+		t.Errorf("weight hangs at %v, want it 2 below the anchor", got)
+	}
+	if got := rope.ReactionForce(weight); got.Distance(Vector{0, 100}) > 1 {
+		t.Errorf("rope pulls with %v, want it to hold up the weight", got)
+	}
+}
+
+func TestConstraint2D(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+
+	space.SetDamping(0.1)
+
+	// a pivot holding up the end of a stick, written as a custom constraint
+	stick := space.AddBody(NewBody(1, MomentForBox(1, 2, 0.1)))
+	stick.SetPosition(Vector{1, 0})
+	pivot := space.AddConstraint(NewConstraint2D(space.StaticBody, stick, func(pivot *Constraint2D, dt float64) (Jacobian, Jacobian, Vector) {
+		a, b := pivot.A(), pivot.B()
+		r1 := AnchorOffset(a, Vector{})
+		r2 := AnchorOffset(b, Vector{-1, 0})
+		delta := b.LocalToWorld(Vector{-1, 0}).Sub(a.LocalToWorld(Vector{}))
+		return NewPointJacobian(r1, r2, Vector{1, 0}), NewPointJacobian(r1, r2, Vector{0, 1}), delta
+	}))
+
+	stepSpace(space, 600)
+	if got := stick.LocalToWorld(Vector{-1, 0}); got.Length() > 0.01 {
+		t.Errorf("stick came off the pivot by %v", got)
+	}
+	if got := stick.Position(); got.Distance(Vector{0, -1}) > 0.05 {
+		t.Errorf("stick hangs at %v, want it straight down", got)
+	}
+	if got := pivot.Class.(*Constraint2D).Impulse().Mult(60); got.Distance(Vector{0, 100}) > 1 {
+		t.Errorf("pivot pushes with %v, want it to hold up the stick", got)
+	}
+}
+
+func TestServoJoint(t *testing.T) {
+	for _, gains := range [][2]float64{{0, 0}, {100, 20}} {
+		space := NewSpace()
+
+		turret := space.AddBody(NewBody(1, 1))
+		space.AddConstraint(NewPivotJoint(space.StaticBody, turret, Vector{}))
+		constraint := space.AddConstraint(NewServoJoint(space.StaticBody, turret, 1, 1000, 2))
+		servo := constraint.Class.(*ServoJoint)
+		servo.Stiffness, servo.Damping = gains[0], gains[1]
+
+		// limited by the max speed on the way there
+		stepSpace(space, 15)
+		if got := turret.AngularVelocity(); got > 2+1e-9 || got < 1.5 {
+			t.Errorf("gains %v: turning at %v, want close to the max speed", gains, got)
+		}
+
+		stepSpace(space, 120)
+		if got := servo.Angle(); math.Abs(got-1) > 0.01 {
+			t.Errorf("gains %v: turned to %v, want 1", gains, got)
+		}
+	}
+}
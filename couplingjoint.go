@@ -0,0 +1,162 @@
+package cp
+
+import (
+	"fmt"
+	"math"
+)
+
+// CouplingJoint gears the motion of two joints together, each of which is a RevoluteJoint or a PrismaticJoint.
+//
+// The coordinate of a joint is its angle for a revolute joint and its translation for a prismatic joint.
+// The coupling keeps coordinate1 + Ratio*coordinate2 constant, so it can link two hinges, two sliders,
+// or a hinge and a slider. Its bodies are the B bodies of the two joints, but like a gear it also pushes
+// on the A bodies of the joints, so all four bodies can move.
+type CouplingJoint struct {
+	*Constraint
+
+	Joint1, Joint2 *Constraint
+	Ratio          float64
+	// Constant is the value of coordinate1 + Ratio*coordinate2 that is kept.
+	Constant float64
+
+	// the jacobian for each of the up to four distinct bodies of the joints
+	rows []couplingRow
+
+	mass, bias, jAcc float64
+}
+
+// couplingRow is the part of a CouplingJoint's jacobian for one body.
+type couplingRow struct {
+	body    *Body
+	linear  Vector
+	angular float64
+}
+
+// NewCouplingJoint gears joint1 and joint2 together with the given ratio, keeping their current coordinates.
+func NewCouplingJoint(joint1, joint2 *Constraint, ratio float64) *Constraint {
+	assert(joint1.b != joint2.b, "Coupled joints must move different bodies")
+
+	joint := &CouplingJoint{
+		Joint1: joint1,
+		Joint2: joint2,
+		Ratio:  ratio,
+	}
+	joint.Constraint = NewConstraint(joint, joint1.b, joint2.b)
+	joint.Constant = joint.position()
+	return joint.Constraint
+}
+
+// jointCoordinate returns the coordinate of a revolute or prismatic joint.
+func jointCoordinate(joint *Constraint) float64 {
+	switch class := joint.Class.(type) {
+	case *RevoluteJoint:
+		return class.Angle()
+	case *PrismaticJoint:
+		return class.Translation()
+	default:
+		panic(fmt.Sprintf("Can't couple %T, only revolute and prismatic joints", joint.Class))
+	}
+}
+
+// jointJacobian returns how the coordinate of a revolute or prismatic joint changes with the motion of
+// its B body (linear, angular) and its A body (frameLinear, frameAngular).
+func jointJacobian(joint *Constraint) (linear Vector, angular float64, frameLinear Vector, frameAngular float64) {
+	a := joint.a
+	b := joint.b
+
+	switch class := joint.Class.(type) {
+	case *RevoluteJoint:
+		return Vector{}, 1, Vector{}, -1
+	case *PrismaticJoint:
+		r1 := a.transform.Vect(class.AnchorA.Sub(a.cog))
+		r2 := b.transform.Vect(class.AnchorB.Sub(b.cog))
+		d := b.p.Add(r2).Sub(a.p.Add(r1))
+		u := a.transform.Vect(class.Axis)
+		return u, r2.Cross(u), u.Neg(), -d.Add(r1).Cross(u)
+	default:
+		panic(fmt.Sprintf("Can't couple %T, only revolute and prismatic joints", joint.Class))
+	}
+}
+
+func (joint *CouplingJoint) position() float64 {
+	return jointCoordinate(joint.Joint1) + joint.Ratio*jointCoordinate(joint.Joint2)
+}
+
+func (joint *CouplingJoint) PreStep(dt float64) {
+	linear1, angular1, frameLinear1, frameAngular1 := jointJacobian(joint.Joint1)
+	linear2, angular2, frameLinear2, frameAngular2 := jointJacobian(joint.Joint2)
+	ratio := joint.Ratio
+
+	joint.rows = joint.rows[:0]
+	joint.addRow(joint.a, linear1, angular1)
+	joint.addRow(joint.b, linear2.Mult(ratio), ratio*angular2)
+	joint.addRow(joint.Joint1.a, frameLinear1, frameAngular1)
+	joint.addRow(joint.Joint2.a, frameLinear2.Mult(ratio), ratio*frameAngular2)
+
+	// bodies shared between the joints are merged above, so their parts add up before they're squared
+	k := 0.0
+	for _, row := range joint.rows {
+		k += row.body.m_inv*row.linear.Dot(row.linear) + row.body.i_inv*row.angular*row.angular
+	}
+	joint.mass = 0
+	if k != 0 {
+		joint.mass = 1 / k
+	}
+
+	c := joint.position() - joint.Constant
+	joint.bias = joint.soft.bias(c, joint.maxBias)
+}
+
+// addRow adds the part of the jacobian for body, merging it with the part already added for the same body.
+func (joint *CouplingJoint) addRow(body *Body, linear Vector, angular float64) {
+	for i := range joint.rows {
+		if row := &joint.rows[i]; row.body == body {
+			row.linear = row.linear.Add(linear)
+			row.angular += angular
+			return
+		}
+	}
+	joint.rows = append(joint.rows, couplingRow{body, linear, angular})
+}
+
+// applyRows applies an impulse along the jacobian to every body.
+func (joint *CouplingJoint) applyRows(impulse float64) {
+	for _, row := range joint.rows {
+		body := row.body
+		body.v = body.v.Add(row.linear.Mult(impulse * body.m_inv))
+		body.w += row.angular * impulse * body.i_inv
+	}
+}
+
+func (joint *CouplingJoint) ApplyCachedImpulse(dt_coef float64) {
+	joint.applyRows(joint.jAcc * dt_coef)
+}
+
+func (joint *CouplingJoint) ApplyImpulse(dt float64) {
+	vr := 0.0
+	for _, row := range joint.rows {
+		vr += row.linear.Dot(row.body.v) + row.angular*row.body.w
+	}
+
+	jMax := joint.maxForce * dt
+	jOld := joint.jAcc
+	joint.jAcc = Clamp(jOld+joint.soft.impulse(joint.mass, joint.bias-vr, jOld), -jMax, jMax)
+	joint.applyRows(joint.jAcc - jOld)
+}
+
+func (joint *CouplingJoint) GetImpulse() float64 {
+	return math.Abs(joint.jAcc)
+}
+
+func (joint *CouplingJoint) reactionImpulses() Jacobian {
+	var j Jacobian
+	for _, row := range joint.rows {
+		switch row.body {
+		case joint.a:
+			j.LinearA, j.AngularA = row.linear.Mult(joint.jAcc), row.angular*joint.jAcc
+		case joint.b:
+			j.LinearB, j.AngularB = row.linear.Mult(joint.jAcc), row.angular*joint.jAcc
+		}
+	}
+	return j
+}
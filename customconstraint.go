@@ -0,0 +1,141 @@
+package cp
+
+import "math"
+
+// Constraint1DPrepareFunc is called at the start of each step to describe a custom one dimensional constraint.
+// It returns the jacobian of the constraint and its position error, which the constraint drives towards 0.
+type Constraint1DPrepareFunc func(constraint *Constraint1D, dt float64) (jacobian Jacobian, c float64)
+
+// Constraint1D solves a custom constraint with one degree of freedom, such as a distance or an angle.
+//
+// Prepare describes the constraint each step, and Constraint1D takes care of the rest: error correction using the
+// constraint's error bias or softness, accumulating impulses, clamping them to the max force and warm starting.
+type Constraint1D struct {
+	*Constraint
+
+	Prepare Constraint1DPrepareFunc
+	// Unilateral constraints only push to keep their error at or above 0, like a limit or a rope.
+	Unilateral bool
+
+	jacobian         Jacobian
+	mass, bias, jAcc float64
+}
+
+// NewConstraint1D creates a custom constraint between a and b that is described by prepare.
+func NewConstraint1D(a, b *Body, prepare Constraint1DPrepareFunc) *Constraint {
+	joint := &Constraint1D{
+		Prepare: prepare,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *Constraint1D) PreStep(dt float64) {
+	var c float64
+	joint.jacobian, c = joint.Prepare(joint, dt)
+	joint.mass = joint.jacobian.EffectiveMass(joint.a, joint.b)
+
+	if joint.Unilateral {
+		joint.bias = joint.soft.limitBias(c, dt, joint.maxBias)
+	} else {
+		joint.bias = joint.soft.bias(c, joint.maxBias)
+	}
+}
+
+func (joint *Constraint1D) ApplyCachedImpulse(dt_coef float64) {
+	joint.jacobian.Apply(joint.a, joint.b, joint.jAcc*dt_coef)
+}
+
+func (joint *Constraint1D) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	jMax := joint.maxForce * dt
+	jMin := -jMax
+	if joint.Unilateral {
+		jMin = 0
+	}
+
+	vr := joint.jacobian.Velocity(a, b)
+	jOld := joint.jAcc
+	joint.jAcc = Clamp(jOld+joint.soft.impulse(joint.mass, joint.bias-vr, jOld), jMin, jMax)
+	joint.jacobian.Apply(a, b, joint.jAcc-jOld)
+}
+
+func (joint *Constraint1D) GetImpulse() float64 {
+	return math.Abs(joint.jAcc)
+}
+
+// Impulse returns the signed impulse applied along the jacobian during the last step.
+func (joint *Constraint1D) Impulse() float64 {
+	return joint.jAcc
+}
+
+func (joint *Constraint1D) reactionImpulses() Jacobian {
+	return joint.jacobian.mult(joint.jAcc)
+}
+
+// Constraint2DPrepareFunc is called at the start of each step to describe a custom two dimensional constraint.
+// It returns the two rows of the constraint's jacobian and the position error along each of them.
+type Constraint2DPrepareFunc func(constraint *Constraint2D, dt float64) (j1, j2 Jacobian, c Vector)
+
+// Constraint2D solves a custom constraint with two coupled degrees of freedom, such as keeping two points together.
+// The rows are solved together, and the length of the accumulated impulse is clamped to the max force.
+type Constraint2D struct {
+	*Constraint
+
+	Prepare Constraint2DPrepareFunc
+
+	j1, j2     Jacobian
+	k          Mat2x2
+	bias, jAcc Vector
+}
+
+// NewConstraint2D creates a custom constraint between a and b that is described by prepare.
+func NewConstraint2D(a, b *Body, prepare Constraint2DPrepareFunc) *Constraint {
+	joint := &Constraint2D{
+		Prepare: prepare,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *Constraint2D) PreStep(dt float64) {
+	var c Vector
+	joint.j1, joint.j2, c = joint.Prepare(joint, dt)
+	joint.k = EffectiveMass2(joint.a, joint.b, &joint.j1, &joint.j2)
+	joint.bias = joint.soft.biasVect(c, joint.maxBias)
+}
+
+func (joint *Constraint2D) apply(j Vector) {
+	joint.j1.Apply(joint.a, joint.b, j.X)
+	joint.j2.Apply(joint.a, joint.b, j.Y)
+}
+
+func (joint *Constraint2D) ApplyCachedImpulse(dt_coef float64) {
+	joint.apply(joint.jAcc.Mult(dt_coef))
+}
+
+func (joint *Constraint2D) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	vr := Vector{joint.j1.Velocity(a, b), joint.j2.Velocity(a, b)}
+	j := joint.soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
+	jOld := joint.jAcc
+	joint.jAcc = jOld.Add(j).Clamp(joint.maxForce * dt)
+	joint.apply(joint.jAcc.Sub(jOld))
+}
+
+func (joint *Constraint2D) GetImpulse() float64 {
+	return joint.jAcc.Length()
+}
+
+// Impulse returns the impulses applied along each row of the jacobian during the last step.
+func (joint *Constraint2D) Impulse() Vector {
+	return joint.jAcc
+}
+
+func (joint *Constraint2D) reactionImpulses() Jacobian {
+	return joint.j1.mult(joint.jAcc.X).add(joint.j2.mult(joint.jAcc.Y))
+}
@@ -10,6 +10,7 @@ type DampedRotarySpring struct {
 
 	targetWrn, wCoef float64
 	iSum, jAcc       float64
+	jSpring          float64
 }
 
 func defaultSpringTorque(spring *DampedRotarySpring, relativeAngle float64) float64 {
@@ -39,6 +40,7 @@ func (spring *DampedRotarySpring) PreStep(dt float64) {
 
 	jSpring := spring.SpringTorqueFunc(spring, a.a-b.a)*dt
 	spring.jAcc = jSpring
+	spring.jSpring = jSpring
 
 	a.w -= jSpring*a.i_inv
 	b.w += jSpring*b.i_inv
@@ -67,3 +69,8 @@ func (spring *DampedRotarySpring) ApplyImpulse(dt float64) {
 func (joint *DampedRotarySpring) GetImpulse() float64 {
 	return joint.jAcc
 }
+
+func (spring *DampedRotarySpring) reactionImpulses() Jacobian {
+	// jAcc adds up the damping impulses that were applied to a
+	return angularImpulses(2*spring.jSpring - spring.jAcc)
+}
@@ -90,3 +90,7 @@ func (spring *DampedSpring) GetImpulse() float64 {
 func DefaultSpringForce(spring *DampedSpring, dist float64) float64 {
 	return (spring.RestLength - dist) * spring.Stiffness
 }
+
+func (spring *DampedSpring) reactionImpulses() Jacobian {
+	return pointImpulses(spring.r1, spring.r2, spring.n.Mult(spring.jAcc))
+}
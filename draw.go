@@ -111,6 +111,62 @@ func DrawConstraint(constraint *Constraint, options Drawer) {
 
 		options.DrawDot(5, a, color, data)
 		options.DrawDot(5, b, color, data)
+	case *RevoluteJoint:
+		joint := constraint.Class.(*RevoluteJoint)
+
+		a := body_a.transform.Point(joint.AnchorA)
+		b := body_b.transform.Point(joint.AnchorB)
+
+		options.DrawDot(5, a, color, data)
+		options.DrawDot(5, b, color, data)
+	case *TargetJoint:
+		joint := constraint.Class.(*TargetJoint)
+
+		a := joint.target
+		b := body_b.transform.Point(joint.AnchorB)
+
+		options.DrawDot(5, a, color, data)
+		options.DrawDot(5, b, color, data)
+		options.DrawSegment(a, b, color, data)
+	case *MotorJoint:
+		joint := constraint.Class.(*MotorJoint)
+
+		a := body_a.transform.Point(joint.LinearOffset)
+		b := body_b.transform.Point(Vector{})
+
+		options.DrawDot(5, a, color, data)
+		options.DrawSegment(a, b, color, data)
+	case *WeldJoint:
+		joint := constraint.Class.(*WeldJoint)
+
+		a := body_a.transform.Point(joint.AnchorA)
+		b := body_b.transform.Point(joint.AnchorB)
+
+		options.DrawDot(5, a, color, data)
+		options.DrawDot(5, b, color, data)
+		options.DrawSegment(body_a.transform.Point(Vector{}), a, color, data)
+		options.DrawSegment(body_b.transform.Point(Vector{}), b, color, data)
+	case *WheelJoint:
+		joint := constraint.Class.(*WheelJoint)
+
+		a := body_a.transform.Point(joint.AnchorA)
+		b := body_b.transform.Point(joint.AnchorB)
+
+		options.DrawDot(5, b, color, data)
+		options.DrawSegment(a, b, color, data)
+	case *PrismaticJoint:
+		joint := constraint.Class.(*PrismaticJoint)
+
+		a := body_a.transform.Point(joint.AnchorA)
+		b := body_b.transform.Point(joint.AnchorB)
+
+		if joint.EnableLimit {
+			axis := body_a.transform.Vect(joint.Axis)
+			options.DrawSegment(a.Add(axis.Mult(joint.Lower)), a.Add(axis.Mult(joint.Upper)), color, data)
+		} else {
+			options.DrawSegment(a, b, color, data)
+		}
+		options.DrawDot(5, b, color, data)
 	case *GrooveJoint:
 		joint := constraint.Class.(*GrooveJoint)
 
@@ -120,6 +176,21 @@ func DrawConstraint(constraint *Constraint, options Drawer) {
 
 		options.DrawDot(5, c, color, data)
 		options.DrawSegment(a, b, color, data)
+	case *PulleyJoint:
+		joint := constraint.Class.(*PulleyJoint)
+
+		a := body_a.transform.Point(joint.AnchorA)
+		b := body_b.transform.Point(joint.AnchorB)
+
+		options.DrawDot(5, joint.GroundAnchorA, color, data)
+		options.DrawDot(5, joint.GroundAnchorB, color, data)
+		options.DrawSegment(a, joint.GroundAnchorA, color, data)
+		options.DrawSegment(joint.GroundAnchorA, joint.GroundAnchorB, color, data)
+		options.DrawSegment(joint.GroundAnchorB, b, color, data)
+	case *FrictionJoint:
+		joint := constraint.Class.(*FrictionJoint)
+
+		options.DrawDot(5, body_b.transform.Point(joint.AnchorB), color, data)
 	case *DampedSpring:
 		spring := constraint.Class.(*DampedSpring)
 		a := body_a.transform.Point(spring.AnchorA)
@@ -147,10 +218,16 @@ func DrawConstraint(constraint *Constraint, options Drawer) {
 		}
 	// these aren't drawn in Chipmunk, so they aren't drawn here
 	case *GearJoint:
+	case *RackAndPinionJoint:
+	case *CouplingJoint:
+	case *Constraint1D:
+	case *Constraint2D:
 	case *SimpleMotor:
+	case *ServoJoint:
 	case *DampedRotarySpring:
 	case *RotaryLimitJoint:
 	case *RatchetJoint:
+	case *softBodyPressure:
 	default:
 		panic(fmt.Sprintf("Implement me: %#v", constraint.Class))
 	}
@@ -3,6 +3,7 @@ package cp
 import (
 	"fmt"
 	"math"
+	"sync/atomic"
 )
 
 const (
@@ -166,6 +167,10 @@ type SplittingPlane struct {
 }
 
 // ShapeFilter is fast collision filtering type that is used to determine if two objects collide before calling collision or query callbacks.
+//
+// The builders for composite objects, such as NewRope and NewRagdoll, take an optional filter for their shapes.
+// Without one the object collides with everything, and a filter such as SHAPE_FILTER_NONE turns collisions off.
+// If the filter has no group, the object is given a new group of its own so that its parts don't collide with each other.
 type ShapeFilter struct {
 	// Two objects with the same non-zero group value do not collide.
 	// This is generally used to group objects in a composite object together to disable self collisions.
@@ -191,6 +196,22 @@ func (a ShapeFilter) Reject(b ShapeFilter) bool {
 		(b.Categories&a.Mask) == 0
 }
 
+// lastCompositeGroup counts the groups handed out to composite objects. They count down from the top so they
+// are unlikely to clash with groups picked by hand.
+var lastCompositeGroup atomic.Uint64
+
+// compositeFilter returns the filter for the shapes of a composite object with the defaults described on ShapeFilter.
+func compositeFilter(opt *ShapeFilter) ShapeFilter {
+	filter := SHAPE_FILTER_ALL
+	if opt != nil {
+		filter = *opt
+	}
+	if filter.Group == NO_GROUP {
+		filter.Group = ^uint(lastCompositeGroup.Add(1))
+	}
+	return filter
+}
+
 // Mat2x2 is a 2x2 matrix type used for tensors and such.
 type Mat2x2 struct {
 	a, b, c, d float64
@@ -336,6 +357,12 @@ KE: %e`, arbiters, maxArbiters,
 		points, maxPoints, len(space.constraints), space.Iterations, constraints, maxConstraints, ke)
 }
 
+// fixedRotation reports whether neither body can rotate.
+// A moment of INFINITY leaves a denormal i_inv instead of 0, so the inverse moments can't just be compared with 0.
+func fixedRotation(a, b *Body) bool {
+	return a.i_inv+b.i_inv == 0 || (a.i == INFINITY && b.i == INFINITY)
+}
+
 func k_scalar_body(body *Body, r, n Vector) float64 {
 	rcn := r.Cross(n)
 	return body.m_inv + body.i_inv*rcn*rcn
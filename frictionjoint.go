@@ -0,0 +1,82 @@
+package cp
+
+import "math"
+
+// FrictionJoint slows down the relative motion of two bodies, like friction against the floor in a top-down game.
+//
+// It applies no more than the constraint's max force to stop the linear motion and MaxTorque to stop the rotation.
+// Unlike damping this can't slow down a body that is being pushed harder than the friction can hold.
+type FrictionJoint struct {
+	*Constraint
+
+	AnchorA, AnchorB Vector
+	MaxTorque        float64
+
+	r1, r2 Vector
+	k      Mat2x2
+	iSum   float64
+
+	jAcc      Vector
+	jAngleAcc float64
+}
+
+// NewFrictionJoint creates a friction joint acting at b's center of gravity, usually with a being the static body.
+func NewFrictionJoint(a, b *Body, maxForce, maxTorque float64) *Constraint {
+	joint := &FrictionJoint{
+		AnchorA:   a.WorldToLocal(b.LocalToWorld(b.cog)),
+		AnchorB:   b.cog,
+		MaxTorque: maxTorque,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	joint.Constraint.SetMaxForce(maxForce)
+	return joint.Constraint
+}
+
+func (joint *FrictionJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r1 = a.transform.Vect(joint.AnchorA.Sub(a.cog))
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+	joint.k = k_tensor(a, b, joint.r1, joint.r2)
+	joint.iSum = 0
+	if iSum := a.i_inv + b.i_inv; iSum > 0 {
+		joint.iSum = 1 / iSum
+	}
+}
+
+func (joint *FrictionJoint) ApplyCachedImpulse(dt_coef float64) {
+	a := joint.a
+	b := joint.b
+
+	apply_impulses(a, b, joint.r1, joint.r2, joint.jAcc.Mult(dt_coef))
+	j := joint.jAngleAcc * dt_coef
+	a.w -= j * a.i_inv
+	b.w += j * b.i_inv
+}
+
+func (joint *FrictionJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	jMax := joint.MaxTorque * dt
+	jAngleOld := joint.jAngleAcc
+	joint.jAngleAcc = Clamp(jAngleOld-(b.w-a.w)*joint.iSum, -jMax, jMax)
+	jAngle := joint.jAngleAcc - jAngleOld
+	a.w -= jAngle * a.i_inv
+	b.w += jAngle * b.i_inv
+
+	vr := relative_velocity(a, b, joint.r1, joint.r2)
+	j := joint.k.Transform(vr.Neg())
+	jOld := joint.jAcc
+	joint.jAcc = jOld.Add(j).Clamp(joint.maxForce * dt)
+	apply_impulses(a, b, joint.r1, joint.r2, joint.jAcc.Sub(jOld))
+}
+
+func (joint *FrictionJoint) GetImpulse() float64 {
+	return math.Sqrt(joint.jAcc.LengthSq() + joint.jAngleAcc*joint.jAngleAcc)
+}
+
+func (joint *FrictionJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.jAcc).add(angularImpulses(joint.jAngleAcc))
+}
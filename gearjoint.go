@@ -32,8 +32,7 @@ func (joint *GearJoint) PreStep(dt float64) {
 	joint.iSum = 1.0 / (a.i_inv*joint.ratio_inv + joint.ratio*b.i_inv)
 
 	// calculate bias velocity
-	maxBias := joint.Constraint.maxBias
-	joint.bias = Clamp(-bias_coef(joint.errorBias, dt)*(b.a*joint.ratio-a.a-joint.phase)/dt, -maxBias, maxBias)
+	joint.bias = joint.soft.bias(b.a*joint.ratio-a.a-joint.phase, joint.maxBias)
 }
 
 func (joint *GearJoint) ApplyCachedImpulse(dt_coef float64) {
@@ -55,7 +54,7 @@ func (joint *GearJoint) ApplyImpulse(dt float64) {
 	jMax := joint.Constraint.maxForce * dt
 
 	// compute normal impulse
-	j := (joint.bias - wr) * joint.iSum
+	j := joint.soft.impulse(joint.iSum, joint.bias-wr, joint.jAcc)
 	jOld := joint.jAcc
 	joint.jAcc = Clamp(jOld+j, -jMax, jMax)
 	j = joint.jAcc - jOld
@@ -68,3 +67,7 @@ func (joint *GearJoint) ApplyImpulse(dt float64) {
 func (joint *GearJoint) GetImpulse() float64 {
 	return math.Abs(joint.jAcc)
 }
+
+func (joint *GearJoint) reactionImpulses() Jacobian {
+	return Jacobian{AngularA: -joint.jAcc * joint.ratio_inv, AngularB: joint.jAcc}
+}
@@ -55,7 +55,7 @@ func (joint *GrooveJoint) PreStep(dt float64) {
 	joint.k = k_tensor(a, b, joint.r1, joint.r2)
 
 	delta := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
-	joint.bias = delta.Mult(-bias_coef(joint.errorBias, dt) / dt).Clamp(joint.maxBias)
+	joint.bias = joint.soft.biasVect(delta, joint.maxBias)
 }
 
 func (joint *GrooveJoint) ApplyCachedImpulse(dt_coef float64) {
@@ -85,7 +85,7 @@ func (joint *GrooveJoint) ApplyImpulse(dt float64) {
 
 	vr := relative_velocity(a, b, r1, r2)
 
-	j := joint.k.Transform(joint.bias.Sub(vr))
+	j := joint.soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
 	jOld := joint.jAcc
 	joint.jAcc = joint.grooveConstrain(jOld.Add(j), dt)
 	j = joint.jAcc.Sub(jOld)
@@ -96,3 +96,7 @@ func (joint *GrooveJoint) ApplyImpulse(dt float64) {
 func (joint *GrooveJoint) GetImpulse() float64 {
 	return joint.jAcc.Length()
 }
+
+func (joint *GrooveJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.jAcc)
+}
@@ -0,0 +1,116 @@
+package cp
+
+// Jacobian is a single row of a constraint's jacobian: how the velocity of the constraint depends on
+// the linear and angular velocities of its two bodies. It is the building block of custom constraints.
+type Jacobian struct {
+	LinearA  Vector
+	AngularA float64
+	LinearB  Vector
+	AngularB float64
+}
+
+// NewPointJacobian returns the jacobian of the distance between two points along the direction n.
+// r1 and r2 are the offsets of the points from the centers of gravity of the bodies, see AnchorOffset.
+func NewPointJacobian(r1, r2, n Vector) Jacobian {
+	return Jacobian{n.Neg(), -r1.Cross(n), n, r2.Cross(n)}
+}
+
+// NewAngularJacobian returns the jacobian of the angle of b relative to a.
+func NewAngularJacobian() Jacobian {
+	return Jacobian{AngularA: -1, AngularB: 1}
+}
+
+// AnchorOffset returns the offset in world coordinates from the center of gravity of body to an anchor in body local coordinates.
+func AnchorOffset(body *Body, anchor Vector) Vector {
+	return body.transform.Vect(anchor.Sub(body.cog))
+}
+
+// Velocity returns the velocity of the constraint.
+func (j *Jacobian) Velocity(a, b *Body) float64 {
+	return j.LinearA.Dot(a.v) + j.AngularA*a.w + j.LinearB.Dot(b.v) + j.AngularB*b.w
+}
+
+// EffectiveMass returns the mass the constraint sees along the row, or 0 if neither body can move along it.
+func (j *Jacobian) EffectiveMass(a, b *Body) float64 {
+	k := j.inverseMass(j, a, b)
+	if k == 0 {
+		return 0
+	}
+	return 1 / k
+}
+
+// inverseMass returns how much an impulse along other changes the velocity along j.
+func (j *Jacobian) inverseMass(other *Jacobian, a, b *Body) float64 {
+	return a.m_inv*j.LinearA.Dot(other.LinearA) + a.i_inv*j.AngularA*other.AngularA +
+		b.m_inv*j.LinearB.Dot(other.LinearB) + b.i_inv*j.AngularB*other.AngularB
+}
+
+// EffectiveMass2 returns the inverse of the effective mass matrix of two coupled rows of a constraint.
+// Transforming a velocity error by it gives the impulse that removes the error along both rows at once.
+func EffectiveMass2(a, b *Body, j1, j2 *Jacobian) Mat2x2 {
+	k11 := j1.inverseMass(j1, a, b)
+	k12 := j1.inverseMass(j2, a, b)
+	k22 := j2.inverseMass(j2, a, b)
+
+	det := k11*k22 - k12*k12
+	assert(det != 0, "Unsolvable constraint")
+	det_inv := 1 / det
+	return Mat2x2{k22 * det_inv, -k12 * det_inv, -k12 * det_inv, k11 * det_inv}
+}
+
+// Apply applies an impulse along the row.
+func (j *Jacobian) Apply(a, b *Body, impulse float64) {
+	a.v = a.v.Add(j.LinearA.Mult(impulse * a.m_inv))
+	a.w += j.AngularA * impulse * a.i_inv
+	b.v = b.v.Add(j.LinearB.Mult(impulse * b.m_inv))
+	b.w += j.AngularB * impulse * b.i_inv
+}
+
+// The impulses a constraint applied to its bodies are stored in a Jacobian too, with the linear impulses
+// and the angular impulses about each body's center of gravity.
+
+// pointImpulses returns the impulses of j being applied to b at r2 and -j to a at r1.
+func pointImpulses(r1, r2, j Vector) Jacobian {
+	return Jacobian{j.Neg(), -r1.Cross(j), j, r2.Cross(j)}
+}
+
+// angularImpulses returns the impulses of a torque impulse j being applied to b and -j to a.
+func angularImpulses(j float64) Jacobian {
+	return Jacobian{AngularA: -j, AngularB: j}
+}
+
+func (j Jacobian) add(other Jacobian) Jacobian {
+	return Jacobian{
+		j.LinearA.Add(other.LinearA), j.AngularA + other.AngularA,
+		j.LinearB.Add(other.LinearB), j.AngularB + other.AngularB,
+	}
+}
+
+func (j Jacobian) mult(s float64) Jacobian {
+	return Jacobian{j.LinearA.Mult(s), j.AngularA * s, j.LinearB.Mult(s), j.AngularB * s}
+}
+
+// KScalar returns the inverse of the effective mass of a point constraint along the normal n.
+func KScalar(a, b *Body, r1, r2, n Vector) float64 {
+	return k_scalar(a, b, r1, r2, n)
+}
+
+// KTensor returns the inverse of the effective mass tensor of a point constraint, like the one used by PivotJoint.
+func KTensor(a, b *Body, r1, r2 Vector) Mat2x2 {
+	return k_tensor(a, b, r1, r2)
+}
+
+// BiasCoef returns the fraction of a constraint's error to correct in a step of length dt.
+func BiasCoef(errorBias, dt float64) float64 {
+	return bias_coef(errorBias, dt)
+}
+
+// RelativeVelocity returns the velocity of the point at r2 on b relative to the point at r1 on a.
+func RelativeVelocity(a, b *Body, r1, r2 Vector) Vector {
+	return relative_velocity(a, b, r1, r2)
+}
+
+// ApplyImpulses applies the impulse j to b at r2 and -j to a at r1.
+func ApplyImpulses(a, b *Body, r1, r2, j Vector) {
+	apply_impulses(a, b, r1, r2, j)
+}
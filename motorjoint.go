@@ -0,0 +1,102 @@
+package cp
+
+import "math"
+
+// MotorJoint drives b towards a position and angle relative to a.
+//
+// LinearOffset is where b's origin should be in a's local coordinates, and AngularOffset is the angle of b
+// relative to a. The constraint's max force limits how hard it pushes and MaxTorque limits how hard it turns.
+// CorrectionFactor is the fraction of the error that is corrected each step, in the range [0, 1].
+// Use SetSoftness to drive b like a damped spring instead.
+type MotorJoint struct {
+	*Constraint
+
+	LinearOffset     Vector
+	AngularOffset    float64
+	MaxTorque        float64
+	CorrectionFactor float64
+
+	r1, r2 Vector
+	k      Mat2x2
+	iSum   float64
+
+	linearBias  Vector
+	angularBias float64
+
+	jAcc      Vector
+	jAngleAcc float64
+}
+
+// NewMotorJoint creates a motor joint that holds b at its current position and angle relative to a.
+func NewMotorJoint(a, b *Body) *Constraint {
+	joint := &MotorJoint{
+		LinearOffset:     a.WorldToLocal(b.Position()),
+		AngularOffset:    b.a - a.a,
+		MaxTorque:        INFINITY,
+		CorrectionFactor: 0.3,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *MotorJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	// the offset is measured between a's target point and b's origin
+	joint.r1 = a.transform.Vect(joint.LinearOffset.Sub(a.cog))
+	joint.r2 = b.transform.Vect(b.cog.Neg())
+	joint.k = k_tensor(a, b, joint.r1, joint.r2)
+	joint.iSum = 0
+	if iSum := a.i_inv + b.i_inv; iSum > 0 {
+		joint.iSum = 1 / iSum
+	}
+
+	delta := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
+	angle := b.a - a.a - joint.AngularOffset
+	if joint.frequency > 0 {
+		joint.linearBias = joint.soft.biasVect(delta, joint.maxBias)
+		joint.angularBias = joint.soft.bias(angle, joint.maxBias)
+	} else {
+		coef := joint.CorrectionFactor / dt
+		joint.linearBias = delta.Mult(-coef).Clamp(joint.maxBias)
+		joint.angularBias = Clamp(-coef*angle, -joint.maxBias, joint.maxBias)
+	}
+}
+
+func (joint *MotorJoint) ApplyCachedImpulse(dt_coef float64) {
+	a := joint.a
+	b := joint.b
+
+	apply_impulses(a, b, joint.r1, joint.r2, joint.jAcc.Mult(dt_coef))
+	j := joint.jAngleAcc * dt_coef
+	a.w -= j * a.i_inv
+	b.w += j * b.i_inv
+}
+
+func (joint *MotorJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	wr := b.w - a.w
+	jMax := joint.MaxTorque * dt
+	jAngleOld := joint.jAngleAcc
+	joint.jAngleAcc = Clamp(jAngleOld+joint.soft.impulse(joint.iSum, joint.angularBias-wr, jAngleOld), -jMax, jMax)
+	jAngle := joint.jAngleAcc - jAngleOld
+	a.w -= jAngle * a.i_inv
+	b.w += jAngle * b.i_inv
+
+	vr := relative_velocity(a, b, joint.r1, joint.r2)
+	jOld := joint.jAcc
+	j := joint.soft.impulseVect(&joint.k, joint.linearBias.Sub(vr), jOld)
+	joint.jAcc = jOld.Add(j).Clamp(joint.maxForce * dt)
+	apply_impulses(a, b, joint.r1, joint.r2, joint.jAcc.Sub(jOld))
+}
+
+func (joint *MotorJoint) GetImpulse() float64 {
+	return math.Sqrt(joint.jAcc.LengthSq() + joint.jAngleAcc*joint.jAngleAcc)
+}
+
+func (joint *MotorJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.jAcc).add(angularImpulses(joint.jAngleAcc))
+}
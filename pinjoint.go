@@ -56,8 +56,7 @@ func (joint *PinJoint) PreStep(dt float64) {
 
 	joint.nMass = 1/k_scalar(a, b, joint.r1, joint.r2, joint.n)
 
-	maxBias := joint.maxBias
-	joint.bias = Clamp(-bias_coef(joint.errorBias, dt)*(dist - joint.Dist)/dt, -maxBias, maxBias)
+	joint.bias = joint.soft.bias(dist-joint.Dist, joint.maxBias)
 }
 
 func (joint *PinJoint) ApplyCachedImpulse(dt_coef float64) {
@@ -74,7 +73,7 @@ func (joint *PinJoint) ApplyImpulse(dt float64) {
 
 	jnMax := joint.maxForce*dt
 
-	jn := (joint.bias - vrn)*joint.nMass
+	jn := joint.soft.impulse(joint.nMass, joint.bias-vrn, joint.jnAcc)
 	jnOld := joint.jnAcc
 	joint.jnAcc = Clamp(jnOld+jn, -jnMax, jnMax)
 	jn = joint.jnAcc - jnOld
@@ -85,3 +84,7 @@ func (joint *PinJoint) ApplyImpulse(dt float64) {
 func (joint *PinJoint) GetImpulse() float64 {
 	return math.Abs(joint.jnAcc)
 }
+
+func (joint *PinJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.n.Mult(joint.jnAcc))
+}
@@ -52,7 +52,7 @@ func (joint *PivotJoint) PreStep(dt float64) {
 
 	// calculate bias velocity
 	delta := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
-	joint.bias = delta.Mult(-bias_coef(joint.Constraint.errorBias, dt) / dt).Clamp(joint.Constraint.maxBias)
+	joint.bias = joint.soft.biasVect(delta, joint.maxBias)
 }
 
 func (joint *PivotJoint) ApplyCachedImpulse(dt_coef float64) {
@@ -70,7 +70,7 @@ func (joint *PivotJoint) ApplyImpulse(dt float64) {
 	vr := relative_velocity(a, b, r1, r2)
 
 	// compute normal impulse
-	j := joint.k.Transform(joint.bias.Sub(vr))
+	j := joint.soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
 	jOld := joint.jAcc
 	joint.jAcc = joint.jAcc.Add(j).Clamp(joint.Constraint.maxForce * dt)
 	j = joint.jAcc.Sub(jOld)
@@ -81,3 +81,7 @@ func (joint *PivotJoint) ApplyImpulse(dt float64) {
 func (joint *PivotJoint) GetImpulse() float64 {
 	return joint.jAcc.Length()
 }
+
+func (joint *PivotJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.jAcc)
+}
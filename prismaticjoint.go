@@ -0,0 +1,177 @@
+package cp
+
+import "math"
+
+// PrismaticJoint lets b slide along a line through the anchor of a without rotating relative to a.
+//
+// The line follows Axis, which is in a's local coordinates. The translation along the axis can be limited
+// to the range [Lower, Upper], and the motor drives the speed along the axis towards MotorSpeed.
+type PrismaticJoint struct {
+	*Constraint
+
+	AnchorA, AnchorB Vector
+	Axis             Vector
+	ReferenceAngle   float64
+
+	EnableLimit  bool
+	Lower, Upper float64
+
+	EnableMotor   bool
+	MotorSpeed    float64
+	MaxMotorForce float64
+
+	r1, r2 Vector
+	// axis and its normal in world coordinates
+	axis, perp Vector
+	// angular parts of the jacobians along the axis and the normal
+	a1, a2, s1, s2 float64
+
+	axialMass   float64
+	k           Mat2x2
+	bias        Vector
+	translation float64
+
+	// impulses of the perpendicular and angular constraints
+	jAcc                            Vector
+	lowerJAcc, upperJAcc, motorJAcc float64
+	lowerBias, upperBias            float64
+}
+
+// NewPrismaticJoint creates a prismatic joint with anchors in body local coordinates and an axis in a's local coordinates.
+// The current relative angle of the bodies is kept.
+func NewPrismaticJoint(a, b *Body, anchorA, anchorB, axis Vector) *Constraint {
+	joint := &PrismaticJoint{
+		AnchorA:        anchorA,
+		AnchorB:        anchorB,
+		Axis:           axis.Normalize(),
+		ReferenceAngle: b.a - a.a,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *PrismaticJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r1 = a.transform.Vect(joint.AnchorA.Sub(a.cog))
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+	d := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
+
+	mSum := a.m_inv + b.m_inv
+	iA := a.i_inv
+	iB := b.i_inv
+
+	joint.axis = a.transform.Vect(joint.Axis)
+	joint.a1 = d.Add(joint.r1).Cross(joint.axis)
+	joint.a2 = joint.r2.Cross(joint.axis)
+	joint.axialMass = 0
+	if k := mSum + iA*joint.a1*joint.a1 + iB*joint.a2*joint.a2; k > 0 {
+		joint.axialMass = 1 / k
+	}
+
+	joint.perp = joint.axis.Perp()
+	joint.s1 = d.Add(joint.r1).Cross(joint.perp)
+	joint.s2 = joint.r2.Cross(joint.perp)
+
+	k11 := mSum + iA*joint.s1*joint.s1 + iB*joint.s2*joint.s2
+	if fixedRotation(a, b) {
+		// only the perpendicular row is left to solve
+		assert(k11 != 0, "Unsolvable constraint")
+		joint.k = Mat2x2{1 / k11, 0, 0, 0}
+	} else {
+		k12 := iA*joint.s1 + iB*joint.s2
+		k22 := iA + iB
+		det := k11*k22 - k12*k12
+		assert(det != 0, "Unsolvable constraint")
+		det_inv := 1 / det
+		joint.k = Mat2x2{k22 * det_inv, -k12 * det_inv, -k12 * det_inv, k11 * det_inv}
+	}
+
+	joint.bias = joint.soft.biasVect(Vector{d.Dot(joint.perp), b.a - a.a - joint.ReferenceAngle}, joint.maxBias)
+
+	joint.translation = d.Dot(joint.axis)
+	if joint.EnableLimit {
+		joint.lowerBias = joint.soft.limitBias(joint.translation-joint.Lower, dt, joint.maxBias)
+		joint.upperBias = joint.soft.limitBias(joint.Upper-joint.translation, dt, joint.maxBias)
+	} else {
+		joint.lowerJAcc = 0
+		joint.upperJAcc = 0
+	}
+	if !joint.EnableMotor {
+		joint.motorJAcc = 0
+	}
+}
+
+func (joint *PrismaticJoint) applyImpulse(j Vector) {
+	a := joint.a
+	b := joint.b
+
+	applyAxisImpulse(a, b, joint.perp, joint.s1, joint.s2, j.X)
+	a.w -= j.Y * a.i_inv
+	b.w += j.Y * b.i_inv
+}
+
+func (joint *PrismaticJoint) ApplyCachedImpulse(dt_coef float64) {
+	a := joint.a
+	b := joint.b
+
+	axial := joint.motorJAcc + joint.lowerJAcc - joint.upperJAcc
+	applyAxisImpulse(a, b, joint.axis, joint.a1, joint.a2, axial*dt_coef)
+	joint.applyImpulse(joint.jAcc.Mult(dt_coef))
+}
+
+func (joint *PrismaticJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	if joint.EnableMotor {
+		vr := axisVelocity(a, b, joint.axis, joint.a1, joint.a2)
+		jMax := joint.MaxMotorForce * dt
+		jOld := joint.motorJAcc
+		joint.motorJAcc = Clamp(jOld+(joint.MotorSpeed-vr)*joint.axialMass, -jMax, jMax)
+		applyAxisImpulse(a, b, joint.axis, joint.a1, joint.a2, joint.motorJAcc-jOld)
+	}
+
+	if joint.EnableLimit {
+		// lower limit
+		vr := axisVelocity(a, b, joint.axis, joint.a1, joint.a2)
+		jOld := joint.lowerJAcc
+		joint.lowerJAcc = math.Max(jOld+joint.soft.impulse(joint.axialMass, joint.lowerBias-vr, jOld), 0)
+		applyAxisImpulse(a, b, joint.axis, joint.a1, joint.a2, joint.lowerJAcc-jOld)
+
+		// upper limit, which pushes the other way
+		vr = -axisVelocity(a, b, joint.axis, joint.a1, joint.a2)
+		jOld = joint.upperJAcc
+		joint.upperJAcc = math.Max(jOld+joint.soft.impulse(joint.axialMass, joint.upperBias-vr, jOld), 0)
+		applyAxisImpulse(a, b, joint.axis, joint.a1, joint.a2, jOld-joint.upperJAcc)
+	}
+
+	vr := Vector{axisVelocity(a, b, joint.perp, joint.s1, joint.s2), b.w - a.w}
+	j := joint.soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
+	jOld := joint.jAcc
+	joint.jAcc = jOld.Add(j).Clamp(joint.maxForce * dt)
+	joint.applyImpulse(joint.jAcc.Sub(jOld))
+}
+
+func (joint *PrismaticJoint) GetImpulse() float64 {
+	axial := joint.motorJAcc + joint.lowerJAcc - joint.upperJAcc
+	return math.Sqrt(joint.jAcc.LengthSq() + axial*axial)
+}
+
+// Translation returns how far the anchor of b is along the axis from the anchor of a.
+func (joint *PrismaticJoint) Translation() float64 {
+	return axisTranslation(joint.a, joint.b, joint.AnchorA, joint.AnchorB, joint.Axis)
+}
+
+// Speed returns how fast the anchor of b is moving along the axis.
+func (joint *PrismaticJoint) Speed() float64 {
+	return axisSpeed(joint.a, joint.b, joint.AnchorA, joint.AnchorB, joint.Axis)
+}
+
+func (joint *PrismaticJoint) reactionImpulses() Jacobian {
+	axial := joint.motorJAcc + joint.lowerJAcc - joint.upperJAcc
+	return axisImpulses(joint.axis, joint.a1, joint.a2, axial).
+		add(axisImpulses(joint.perp, joint.s1, joint.s2, joint.jAcc.X)).
+		add(angularImpulses(joint.jAcc.Y))
+}
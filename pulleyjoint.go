@@ -0,0 +1,166 @@
+package cp
+
+// PulleyJoint hangs two bodies from a rope running over two fixed pulleys.
+//
+// The rope runs from AnchorA on a up to GroundAnchorA, across to GroundAnchorB and down to AnchorB on b.
+// LengthA + Ratio*LengthB is kept equal to Length, so pulling one side down lifts the other.
+// Set Slack to let the rope go slack instead, so it only keeps LengthA + Ratio*LengthB from exceeding Length.
+// MaxLengthA and MaxLengthB optionally limit how far each side can extend.
+type PulleyJoint struct {
+	*Constraint
+
+	GroundAnchorA, GroundAnchorB Vector
+	AnchorA, AnchorB             Vector
+	Ratio                        float64
+	Length                       float64
+	Slack                        bool
+
+	MaxLengthA, MaxLengthB float64
+
+	r1, r2       Vector
+	u1, u2       Vector
+	length1      float64
+	length2      float64
+	mass         float64
+	mass1, mass2 float64
+	bias         float64
+	bias1, bias2 float64
+	jAcc         float64
+	jAcc1, jAcc2 float64
+}
+
+// NewPulleyJoint creates a pulley joint with ground anchors in world coordinates and body anchors in local coordinates.
+// The rope's length is taken from the current positions of the bodies.
+func NewPulleyJoint(a, b *Body, groundAnchorA, groundAnchorB, anchorA, anchorB Vector, ratio float64) *Constraint {
+	assert(ratio > 0, "Pulley ratio must be positive")
+
+	joint := &PulleyJoint{
+		GroundAnchorA: groundAnchorA,
+		GroundAnchorB: groundAnchorB,
+		AnchorA:       anchorA,
+		AnchorB:       anchorB,
+		Ratio:         ratio,
+		MaxLengthA:    INFINITY,
+		MaxLengthB:    INFINITY,
+	}
+	joint.Length = a.LocalToWorld(anchorA).Distance(groundAnchorA) + ratio*b.LocalToWorld(anchorB).Distance(groundAnchorB)
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+// LengthA returns the current length of the rope on a's side.
+func (joint *PulleyJoint) LengthA() float64 {
+	return joint.a.LocalToWorld(joint.AnchorA).Distance(joint.GroundAnchorA)
+}
+
+// LengthB returns the current length of the rope on b's side.
+func (joint *PulleyJoint) LengthB() float64 {
+	return joint.b.LocalToWorld(joint.AnchorB).Distance(joint.GroundAnchorB)
+}
+
+// pulleySide returns the direction from a ground anchor to a body anchor, its length and the effective mass along it.
+func pulleySide(body *Body, r, ground Vector) (u Vector, length, k float64) {
+	u = body.p.Add(r).Sub(ground)
+	length = u.Length()
+	if length > 0 {
+		u = u.Mult(1 / length)
+	}
+	rn := r.Cross(u)
+	return u, length, body.m_inv + body.i_inv*rn*rn
+}
+
+func (joint *PulleyJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r1 = a.transform.Vect(joint.AnchorA.Sub(a.cog))
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+
+	var k1, k2 float64
+	joint.u1, joint.length1, k1 = pulleySide(a, joint.r1, joint.GroundAnchorA)
+	joint.u2, joint.length2, k2 = pulleySide(b, joint.r2, joint.GroundAnchorB)
+
+	joint.mass = 0
+	if k := k1 + joint.Ratio*joint.Ratio*k2; k > 0 {
+		joint.mass = 1 / k
+	}
+	if c := joint.Length - joint.length1 - joint.Ratio*joint.length2; joint.Slack {
+		joint.bias = joint.soft.limitBias(c, dt, joint.maxBias)
+	} else {
+		joint.bias = joint.soft.bias(c, joint.maxBias)
+	}
+
+	joint.mass1, joint.bias1 = 0, 0
+	if joint.MaxLengthA < INFINITY && k1 > 0 {
+		joint.mass1 = 1 / k1
+		joint.bias1 = joint.soft.limitBias(joint.MaxLengthA-joint.length1, dt, joint.maxBias)
+	} else {
+		joint.jAcc1 = 0
+	}
+	joint.mass2, joint.bias2 = 0, 0
+	if joint.MaxLengthB < INFINITY && k2 > 0 {
+		joint.mass2 = 1 / k2
+		joint.bias2 = joint.soft.limitBias(joint.MaxLengthB-joint.length2, dt, joint.maxBias)
+	} else {
+		joint.jAcc2 = 0
+	}
+}
+
+// applyRopeImpulses pulls each body towards its ground anchor.
+func (joint *PulleyJoint) applyRopeImpulses(j1, j2 float64) {
+	apply_impulse(joint.a, joint.u1.Mult(-j1), joint.r1)
+	apply_impulse(joint.b, joint.u2.Mult(-j2), joint.r2)
+}
+
+// ropeSpeeds returns how fast each side of the rope is getting longer.
+func (joint *PulleyJoint) ropeSpeeds() (float64, float64) {
+	a := joint.a
+	b := joint.b
+
+	v1 := a.v.Add(joint.r1.Perp().Mult(a.w))
+	v2 := b.v.Add(joint.r2.Perp().Mult(b.w))
+	return joint.u1.Dot(v1), joint.u2.Dot(v2)
+}
+
+func (joint *PulleyJoint) ApplyCachedImpulse(dt_coef float64) {
+	j := joint.jAcc * dt_coef
+	joint.applyRopeImpulses(j+joint.jAcc1*dt_coef, joint.Ratio*j+joint.jAcc2*dt_coef)
+}
+
+func (joint *PulleyJoint) ApplyImpulse(dt float64) {
+	jMax := joint.maxForce * dt
+
+	// a slack rope can only pull
+	jMin := -jMax
+	if joint.Slack {
+		jMin = 0
+	}
+	s1, s2 := joint.ropeSpeeds()
+	jOld := joint.jAcc
+	joint.jAcc = Clamp(jOld+joint.soft.impulse(joint.mass, joint.bias+s1+joint.Ratio*s2, jOld), jMin, jMax)
+	j := joint.jAcc - jOld
+	joint.applyRopeImpulses(j, joint.Ratio*j)
+
+	if joint.mass1 != 0 {
+		s1, _ = joint.ropeSpeeds()
+		jOld = joint.jAcc1
+		joint.jAcc1 = Clamp(jOld+joint.soft.impulse(joint.mass1, joint.bias1+s1, jOld), 0, jMax)
+		joint.applyRopeImpulses(joint.jAcc1-jOld, 0)
+	}
+	if joint.mass2 != 0 {
+		_, s2 = joint.ropeSpeeds()
+		jOld = joint.jAcc2
+		joint.jAcc2 = Clamp(jOld+joint.soft.impulse(joint.mass2, joint.bias2+s2, jOld), 0, jMax)
+		joint.applyRopeImpulses(0, joint.jAcc2-jOld)
+	}
+}
+
+func (joint *PulleyJoint) GetImpulse() float64 {
+	return joint.jAcc
+}
+
+func (joint *PulleyJoint) reactionImpulses() Jacobian {
+	j1 := joint.u1.Mult(-joint.jAcc - joint.jAcc1)
+	j2 := joint.u2.Mult(-joint.Ratio*joint.jAcc - joint.jAcc2)
+	return Jacobian{j1, joint.r1.Cross(j1), j2, joint.r2.Cross(j2)}
+}
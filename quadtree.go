@@ -0,0 +1,309 @@
+package cp
+
+import "math"
+
+// QuadTree is a loose quadtree spatial index.
+//
+// Each shape is stored once, in the deepest node whose loose bounds (the node's bounds grown to twice their size)
+// contain it. Shapes whose center lies outside of the world bounds are kept in the root node.
+// Empty branches are pruned, so it stays small for very large and sparse worlds.
+type QuadTree struct {
+	*SpatialIndex
+
+	bounds   BB
+	maxDepth int
+
+	root      *QuadNode
+	handleSet *HashSet[*Shape, *QuadHandle]
+
+	stamp uint
+}
+
+type QuadNode struct {
+	parent   *QuadNode
+	children [4]*QuadNode
+
+	bb, loose BB
+	depth     int
+
+	handles []*QuadHandle
+	// number of handles in this node and all of its descendants
+	count int
+}
+
+type QuadHandle struct {
+	obj   *Shape
+	bb    BB
+	node  *QuadNode
+	index int
+	stamp uint
+}
+
+// NewQuadTree creates a loose quadtree covering bounds that subdivides at most maxDepth times.
+func NewQuadTree(bounds BB, maxDepth int, bbfunc SpatialIndexBB, staticIndex *SpatialIndex) *SpatialIndex {
+	assert(bounds.R > bounds.L && bounds.T > bounds.B, "QuadTree bounds must have a positive area")
+	assert(maxDepth >= 0, "QuadTree max depth must not be negative")
+
+	tree := &QuadTree{
+		bounds:   bounds,
+		maxDepth: maxDepth,
+		handleSet: NewHashSet[*Shape, *QuadHandle](func(obj *Shape, elt *QuadHandle) bool {
+			return obj == elt.obj
+		}),
+	}
+	tree.root = newQuadNode(nil, bounds)
+	spatialIndex := NewSpatialIndex(tree, bbfunc, staticIndex)
+	tree.SpatialIndex = spatialIndex
+	return spatialIndex
+}
+
+func newQuadNode(parent *QuadNode, bb BB) *QuadNode {
+	hw := (bb.R - bb.L) * 0.5
+	hh := (bb.T - bb.B) * 0.5
+	node := &QuadNode{
+		parent: parent,
+		bb:     bb,
+		loose:  BB{bb.L - hw, bb.B - hh, bb.R + hw, bb.T + hh},
+	}
+	if parent != nil {
+		node.depth = parent.depth + 1
+	}
+	return node
+}
+
+// quadrant returns the index of the child of node that contains p.
+func (node *QuadNode) quadrant(p Vector) int {
+	center := node.bb.Center()
+	i := 0
+	if p.X >= center.X {
+		i |= 1
+	}
+	if p.Y >= center.Y {
+		i |= 2
+	}
+	return i
+}
+
+func (node *QuadNode) child(i int) *QuadNode {
+	if node.children[i] == nil {
+		center := node.bb.Center()
+		bb := node.bb
+		if i&1 != 0 {
+			bb.L = center.X
+		} else {
+			bb.R = center.X
+		}
+		if i&2 != 0 {
+			bb.B = center.Y
+		} else {
+			bb.T = center.Y
+		}
+		node.children[i] = newQuadNode(node, bb)
+	}
+	return node.children[i]
+}
+
+// locate returns the node that a shape with the given bounding box belongs in, creating it if needed.
+func (tree *QuadTree) locate(bb BB) *QuadNode {
+	node := tree.root
+	center := bb.Center()
+	if !tree.bounds.ContainsVect(center) {
+		return node
+	}
+
+	hw := (bb.R - bb.L) * 0.5
+	hh := (bb.T - bb.B) * 0.5
+	for node.depth < tree.maxDepth {
+		// A child's loose bounds extend a quarter of this node's size past its own bounds.
+		if hw > (node.bb.R-node.bb.L)*0.25 || hh > (node.bb.T-node.bb.B)*0.25 {
+			break
+		}
+		node = node.child(node.quadrant(center))
+	}
+	return node
+}
+
+func (node *QuadNode) add(hand *QuadHandle) {
+	hand.node = node
+	hand.index = len(node.handles)
+	node.handles = append(node.handles, hand)
+
+	for n := node; n != nil; n = n.parent {
+		n.count++
+	}
+}
+
+func (node *QuadNode) remove(index int) {
+	last := len(node.handles) - 1
+	moved := node.handles[last]
+	node.handles[index] = moved
+	moved.index = index
+	node.handles[last] = nil
+	node.handles = node.handles[:last]
+
+	for n := node; n != nil; n = n.parent {
+		n.count--
+	}
+
+	// Prune the branch if it became empty.
+	for n := node; n.parent != nil && n.count == 0; n = n.parent {
+		for i, child := range n.parent.children {
+			if child == n {
+				n.parent.children[i] = nil
+			}
+		}
+	}
+}
+
+func (tree *QuadTree) update(hand *QuadHandle) {
+	hand.bb = tree.bbfunc(hand.obj)
+	node := tree.locate(hand.bb)
+	if node != hand.node {
+		// Add before removing so pruning the old branch can't detach the new node.
+		old, index := hand.node, hand.index
+		node.add(hand)
+		old.remove(index)
+	}
+}
+
+func (tree *QuadTree) Count() int {
+	return int(tree.handleSet.Count())
+}
+
+func (tree *QuadTree) Each(f SpatialIndexIterator) {
+	tree.handleSet.Each(func(hand *QuadHandle) {
+		f(hand.obj)
+	})
+}
+
+func (tree *QuadTree) Contains(obj *Shape, hashId HashValue) bool {
+	return tree.handleSet.Find(hashId, obj) != nil
+}
+
+func (tree *QuadTree) Insert(obj *Shape, hashId HashValue) {
+	hand := tree.handleSet.Insert(hashId, obj, func(obj *Shape) *QuadHandle {
+		return &QuadHandle{obj: obj}
+	})
+	if hand.node != nil {
+		return
+	}
+
+	hand.bb = tree.bbfunc(obj)
+	tree.locate(hand.bb).add(hand)
+}
+
+func (tree *QuadTree) Remove(obj *Shape, hashId HashValue) {
+	hand := tree.handleSet.Remove(hashId, obj)
+
+	if hand != nil {
+		hand.node.remove(hand.index)
+		hand.node = nil
+		hand.obj = nil
+	}
+}
+
+func (tree *QuadTree) Reindex() {
+	tree.handleSet.Each(tree.update)
+}
+
+func (tree *QuadTree) ReindexObject(obj *Shape, hashId HashValue) {
+	hand := tree.handleSet.Find(hashId, obj)
+
+	if hand != nil {
+		tree.update(hand)
+	}
+}
+
+func (tree *QuadTree) ReindexQuery(f SpatialIndexQuery, data any) {
+	tree.Reindex()
+
+	// Loose bounds of sibling nodes overlap, so every handle is checked against the whole tree.
+	// Handles are stamped once they have been checked so that each pair is only reported once.
+	tree.stamp++
+	tree.handleSet.Each(func(hand *QuadHandle) {
+		hand.stamp = tree.stamp
+		tree.root.pairQuery(hand, tree.stamp, f, data)
+	})
+
+	tree.CollideStatic(tree.staticIndex, f, data)
+}
+
+func (node *QuadNode) pairQuery(hand *QuadHandle, stamp uint, f SpatialIndexQuery, data any) {
+	if node.count == 0 || (node.parent != nil && !node.loose.Intersects(hand.bb)) {
+		return
+	}
+
+	for _, other := range node.handles {
+		if other.stamp != stamp && hand.bb.Intersects(other.bb) {
+			f(hand.obj, other.obj, 0, data)
+		}
+	}
+
+	for _, child := range node.children {
+		if child != nil {
+			child.pairQuery(hand, stamp, f, data)
+		}
+	}
+}
+
+func (tree *QuadTree) Query(obj any, bb BB, f SpatialIndexQuery, data any) {
+	tree.root.query(obj, bb, f, data)
+}
+
+func (node *QuadNode) query(obj any, bb BB, f SpatialIndexQuery, data any) {
+	// The root also holds shapes outside of the world bounds, so it is always searched.
+	if node.count == 0 || (node.parent != nil && !node.loose.Intersects(bb)) {
+		return
+	}
+
+	for _, hand := range node.handles {
+		if hand.obj != obj && hand.bb.Intersects(bb) {
+			f(obj, hand.obj, 0, data)
+		}
+	}
+
+	for _, child := range node.children {
+		if child != nil {
+			child.query(obj, bb, f, data)
+		}
+	}
+}
+
+func (tree *QuadTree) SegmentQuery(obj any, a, b Vector, t_exit float64, f SpatialIndexSegmentQuery, data any) {
+	tree.root.segmentQuery(obj, a, b, t_exit, f, data)
+}
+
+func (node *QuadNode) segmentQuery(obj any, a, b Vector, t_exit float64, f SpatialIndexSegmentQuery, data any) float64 {
+	if node.count == 0 || (node.parent != nil && node.loose.SegmentQuery(a, b) >= t_exit) {
+		return t_exit
+	}
+
+	for _, hand := range node.handles {
+		if hand.bb.SegmentQuery(a, b) < t_exit {
+			t_exit = math.Min(t_exit, f(obj, hand.obj, data))
+		}
+	}
+
+	// Visit the nearest children first so that t_exit shrinks as early as possible.
+	var order [4]int
+	var ts [4]float64
+	n := 0
+	for i, child := range node.children {
+		if child == nil {
+			continue
+		}
+		t := child.loose.SegmentQuery(a, b)
+		j := n
+		for j > 0 && ts[j-1] > t {
+			order[j], ts[j] = order[j-1], ts[j-1]
+			j--
+		}
+		order[j], ts[j] = i, t
+		n++
+	}
+	for _, i := range order[:n] {
+		t_exit = node.children[i].segmentQuery(obj, a, b, t_exit, f, data)
+	}
+
+	return t_exit
+}
@@ -0,0 +1,97 @@
+package cp
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestQuadTree_ReindexQuery(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	bounds := NewBB(-1000, -1000, 1000, 1000)
+	index := NewQuadTree(bounds, 8, ShapeGetBB, nil)
+
+	var shapes []*Shape
+	for i := range 300 {
+		body := NewKinematicBody()
+		// some shapes are placed outside of the bounds on purpose
+		body.SetPosition(Vector{rng.Float64()*2400 - 1200, rng.Float64()*2400 - 1200})
+		shape := NewCircle(body, 1+rng.Float64()*rng.Float64()*200, Vector{})
+		shape.SetHashId(HashValue(i + 1))
+		shape.CacheBB()
+		index.class.Insert(shape, shape.hashid)
+		shapes = append(shapes, shape)
+	}
+
+	// move everything around to exercise reinsertion and pruning
+	for _, shape := range shapes {
+		shape.body.SetPosition(shape.body.Position().Add(Vector{rng.Float64()*200 - 100, rng.Float64()*200 - 100}))
+		shape.CacheBB()
+	}
+	for _, shape := range shapes[:100] {
+		index.class.Remove(shape, shape.hashid)
+	}
+	shapes = shapes[100:]
+
+	expected := map[ShapePair]bool{}
+	for i, a := range shapes {
+		for _, b := range shapes[i+1:] {
+			if a.bb.Intersects(b.bb) {
+				expected[ShapePair{a, b}] = true
+			}
+		}
+	}
+
+	found := map[ShapePair]bool{}
+	index.class.ReindexQuery(func(obj any, b *Shape, collisionId uint32, data any) uint32 {
+		a := obj.(*Shape)
+		pair := ShapePair{a, b}
+		if !expected[pair] {
+			pair = ShapePair{b, a}
+		}
+		if !expected[pair] {
+			t.Errorf("Unexpected pair reported %v %v", a.bb, b.bb)
+		}
+		if found[pair] {
+			t.Errorf("Pair reported twice %v %v", a.bb, b.bb)
+		}
+		found[pair] = true
+		return collisionId
+	}, nil)
+
+	if len(found) != len(expected) {
+		t.Errorf("Found %v pairs, expected %v", len(found), len(expected))
+	}
+
+	if got := index.class.Count(); got != len(shapes) {
+		t.Errorf("Count is %v, expected %v", got, len(shapes))
+	}
+}
+
+func TestQuadTree_Space(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	quadTree := func(bbfunc SpatialIndexBB, staticIndex *SpatialIndex) *SpatialIndex {
+		return NewQuadTree(NewBB(-1000, -1000, 1000, 1000), 10, bbfunc, staticIndex)
+	}
+	space.UseSpatialIndex(quadTree, quadTree)
+
+	ground := space.AddShape(NewSegment(space.StaticBody, Vector{-100, 0}, Vector{100, 0}, 0))
+	body := space.AddBody(NewBody(1, MomentForCircle(1, 0, 5, Vector{})))
+	body.SetPosition(Vector{0, 20})
+	space.AddShape(NewCircle(body, 5, Vector{}))
+
+	for range 120 {
+		space.Step(1.0 / 60.0)
+	}
+	if body.Position().Y < 4 {
+		t.Errorf("Circle fell through the ground: %v", body.Position())
+	}
+
+	info := space.SegmentQueryFirst(Vector{0, 100}, Vector{0, -100}, 0, SHAPE_FILTER_ALL)
+	if info.Shape == nil || info.Shape.body != body {
+		t.Error("Expected the segment query to hit the circle first")
+	}
+	if nearest := space.PointQueryNearest(Vector{50, -1}, 10, SHAPE_FILTER_ALL); nearest.Shape != ground {
+		t.Error("Expected the nearest shape to be the ground")
+	}
+}
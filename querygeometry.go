@@ -0,0 +1,195 @@
+package cp
+
+// castTolerance is the distance at which a cast is considered to have hit a shape.
+const castTolerance = 1e-3
+
+// QueryGeometry is a circle, capsule or convex polygon in world coordinates used to query a space
+// without having to create a body and shape for it.
+type QueryGeometry struct {
+	shape *Shape
+}
+
+// NewCircleGeometry creates circle query geometry.
+func NewCircleGeometry(center Vector, radius float64) *QueryGeometry {
+	return newQueryGeometry(NewCircle(NewKinematicBody(), radius, center))
+}
+
+// NewCapsuleGeometry creates capsule (beveled segment) query geometry.
+func NewCapsuleGeometry(a, b Vector, radius float64) *QueryGeometry {
+	return newQueryGeometry(NewSegment(NewKinematicBody(), a, b, radius))
+}
+
+// NewPolyGeometry creates convex polygon query geometry.
+// The convex hull of the verts is used after they have been transformed into world coordinates by transform.
+func NewPolyGeometry(verts []Vector, transform Transform, radius float64) *QueryGeometry {
+	return newQueryGeometry(NewPolyShape(NewKinematicBody(), len(verts), verts, transform, radius))
+}
+
+func newQueryGeometry(shape *Shape) *QueryGeometry {
+	shape.Update(NewTransformIdentity())
+	return &QueryGeometry{shape}
+}
+
+// BB returns the bounding box of the geometry.
+func (geometry *QueryGeometry) BB() BB {
+	return geometry.shape.bb
+}
+
+// GeometryQuery calls callback for each shape that overlaps the geometry.
+// Returns true if any of the overlapping shapes were not sensors, like ShapeQuery.
+func (space *Space) GeometryQuery(geometry *QueryGeometry, filter ShapeFilter, callback func(shape *Shape, points *ContactPointSet)) bool {
+	geometry.shape.Filter = filter
+	return space.ShapeQuery(geometry.shape, callback)
+}
+
+// GeometryCastInfo is the result of casting query geometry.
+type GeometryCastInfo struct {
+	// The shape that was hit, nil if nothing was hit.
+	Shape *Shape
+	// The fraction of the translation the geometry moved before touching the shape, in the range [0, 1].
+	// It is 0 if the geometry already overlapped the shape.
+	Alpha float64
+	// The normal of the surface hit.
+	Normal Vector
+	// The closest points between the geometry at Alpha (PointA) and the shape hit (PointB).
+	Points ContactPointSet
+}
+
+// GeometryCast sweeps the geometry along translation and calls callback for every shape it would touch, in no particular order.
+func (space *Space) GeometryCast(geometry *QueryGeometry, translation Vector, filter ShapeFilter, callback func(info *GeometryCastInfo)) {
+	space.Lock()
+	space.geometryCast(geometry, translation, filter, func(shape *Shape) {
+		var info GeometryCastInfo
+		if geometry.cast(translation, shape, &info) {
+			callback(&info)
+		}
+	})
+	space.Unlock(true)
+}
+
+// GeometryCastFirst sweeps the geometry along translation and returns the first shape it would touch.
+// Sensor shapes are ignored.
+func (space *Space) GeometryCastFirst(geometry *QueryGeometry, translation Vector, filter ShapeFilter) GeometryCastInfo {
+	first := GeometryCastInfo{Alpha: 1}
+	space.geometryCast(geometry, translation, filter, func(shape *Shape) {
+		var info GeometryCastInfo
+		if !shape.sensor && geometry.cast(translation, shape, &info) && (first.Shape == nil || info.Alpha < first.Alpha) {
+			first = info
+		}
+	})
+	return first
+}
+
+func (space *Space) geometryCast(geometry *QueryGeometry, translation Vector, filter ShapeFilter, f func(shape *Shape)) {
+	bb := geometry.shape.bb
+	bb = bb.Merge(bb.Offset(translation))
+
+	query := func(_ any, shape *Shape, collisionId uint32, _ any) uint32 {
+		if !shape.Filter.Reject(filter) && shape.bb.Intersects(bb) {
+			f(shape)
+		}
+		return collisionId
+	}
+	space.dynamicShapes.class.Query(geometry, bb, query, nil)
+	space.staticShapes.class.Query(geometry, bb, query, nil)
+}
+
+// cast finds when the geometry first touches shape using conservative advancement.
+func (geometry *QueryGeometry) cast(translation Vector, shape *Shape, info *GeometryCastInfo) bool {
+	g := geometry.shape
+	// put the geometry back where it started when done
+	defer g.Update(NewTransformIdentity())
+
+	alpha := 0.0
+	for range maxGjkIterations {
+		g.Update(NewTransformTranslate(translation.Mult(alpha)))
+		points := shapesClosestPoints(g, shape)
+		dist := points.d - shapeRadius(g) - shapeRadius(shape)
+
+		if dist <= castTolerance {
+			info.Shape = shape
+			info.Alpha = alpha
+			info.Normal = points.n.Neg()
+			info.Points.Count = 1
+			info.Points.Normal = points.n
+			info.Points.Points[0].PointA = points.a.Add(points.n.Mult(shapeRadius(g)))
+			info.Points.Points[0].PointB = points.b.Sub(points.n.Mult(shapeRadius(shape)))
+			info.Points.Points[0].Distance = dist
+			return true
+		}
+
+		// The distance can shrink no faster than the geometry approaches along the separating axis.
+		approach := translation.Dot(points.n)
+		if approach <= 0 {
+			return false
+		}
+		alpha += dist / approach
+		if alpha > 1 {
+			return false
+		}
+	}
+	return false
+}
+
+// shapesClosestPoints finds the closest points between the cores of two shapes, ignoring their radii.
+// The normal points from a to b, and the distance is negative when the cores overlap.
+func shapesClosestPoints(a, b *Shape) ClosestPoints {
+	if a.Order() > b.Order() {
+		points := shapesClosestPoints(b, a)
+		return ClosestPoints{points.b, points.a, points.n.Neg(), points.d, points.collisionId}
+	}
+
+	// GJK can't find a separating axis when one of the shapes is a single point and the other is a point or a line.
+	if circle, ok := a.Class.(*Circle); ok {
+		var closest Vector
+		switch other := b.Class.(type) {
+		case *Circle:
+			closest = other.tc
+		case *Segment:
+			closest = circle.tc.ClosestPointOnSegment(other.ta, other.tb)
+		default:
+			return shapesClosestPointsGJK(a, b)
+		}
+
+		delta := closest.Sub(circle.tc)
+		d := delta.Length()
+		n := Vector{1, 0}
+		if d != 0 {
+			n = delta.Mult(1 / d)
+		}
+		return ClosestPoints{a: circle.tc, b: closest, n: n, d: d}
+	}
+
+	return shapesClosestPointsGJK(a, b)
+}
+
+func shapesClosestPointsGJK(a, b *Shape) ClosestPoints {
+	var collisionId uint32
+	return GJK(SupportContext{a, b, shapeSupportFunc(a), shapeSupportFunc(b)}, &collisionId)
+}
+
+func shapeSupportFunc(shape *Shape) SupportPointFunc {
+	switch shape.Class.(type) {
+	case *Circle:
+		return CircleSupportPoint
+	case *Segment:
+		return SegmentSupportPoint
+	case *PolyShape:
+		return PolySupportPoint
+	default:
+		panic("Unknown shape type")
+	}
+}
+
+func shapeRadius(shape *Shape) float64 {
+	switch class := shape.Class.(type) {
+	case *Circle:
+		return class.r
+	case *Segment:
+		return class.r
+	case *PolyShape:
+		return class.r
+	default:
+		panic("Unknown shape type")
+	}
+}
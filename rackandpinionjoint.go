@@ -0,0 +1,81 @@
+package cp
+
+import "math"
+
+// RackAndPinionJoint makes a pinion (a) roll along a rack (b) as if their teeth were meshed.
+//
+// Axis is the direction of the rack in b's local coordinates. When the pinion moves by some distance along the axis
+// it turns by that distance divided by Radius, clockwise for a positive radius, as when sitting on top of the rack.
+// The bodies are otherwise free, so the rack is usually held by a PrismaticJoint and the pinion by a RevoluteJoint.
+type RackAndPinionJoint struct {
+	*Constraint
+
+	Axis   Vector
+	Radius float64
+	// Phase is the value of the coupled position that is kept constant.
+	Phase float64
+
+	jacobian         Jacobian
+	mass, bias, jAcc float64
+}
+
+// NewRackAndPinionJoint couples the rotation of pinion to its movement along rack, keeping their current positions.
+func NewRackAndPinionJoint(pinion, rack *Body, axis Vector, radius float64) *Constraint {
+	joint := &RackAndPinionJoint{
+		Axis:   axis.Normalize(),
+		Radius: radius,
+	}
+	joint.Constraint = NewConstraint(joint, pinion, rack)
+	joint.Phase = joint.position()
+	return joint.Constraint
+}
+
+// position returns how far the pinion has moved along the rack plus how far it has rolled.
+func (joint *RackAndPinionJoint) position() float64 {
+	a := joint.a
+	b := joint.b
+
+	u := b.transform.Vect(joint.Axis)
+	return a.p.Sub(b.p).Dot(u) + joint.Radius*(a.a-b.a)
+}
+
+func (joint *RackAndPinionJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	u := b.transform.Vect(joint.Axis)
+	d := a.p.Sub(b.p)
+	joint.jacobian = Jacobian{
+		LinearA:  u,
+		AngularA: joint.Radius,
+		LinearB:  u.Neg(),
+		// the axis turns with the rack
+		AngularB: u.Cross(d) - joint.Radius,
+	}
+	joint.mass = joint.jacobian.EffectiveMass(a, b)
+
+	c := joint.position() - joint.Phase
+	joint.bias = joint.soft.bias(c, joint.maxBias)
+}
+
+func (joint *RackAndPinionJoint) ApplyCachedImpulse(dt_coef float64) {
+	joint.jacobian.Apply(joint.a, joint.b, joint.jAcc*dt_coef)
+}
+
+func (joint *RackAndPinionJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	jMax := joint.maxForce * dt
+	jOld := joint.jAcc
+	joint.jAcc = Clamp(jOld+joint.soft.impulse(joint.mass, joint.bias-joint.jacobian.Velocity(a, b), jOld), -jMax, jMax)
+	joint.jacobian.Apply(a, b, joint.jAcc-jOld)
+}
+
+func (joint *RackAndPinionJoint) GetImpulse() float64 {
+	return math.Abs(joint.jAcc)
+}
+
+func (joint *RackAndPinionJoint) reactionImpulses() Jacobian {
+	return joint.jacobian.mult(joint.jAcc)
+}
@@ -0,0 +1,162 @@
+package cp
+
+import "math"
+
+// Bone describes one capsule shaped body of a ragdoll and the joint connecting it to its parent bone.
+type Bone struct {
+	Name string
+	// Parent is the index of the parent bone in the skeleton. The first bone is the root and must have a Parent of -1.
+	Parent int
+	// Attach is how far along the parent bone this bone is joined to it, from 0 at its start to 1 at its end.
+	Attach float64
+
+	Length, Width, Mass float64
+
+	// Angle is the direction of the bone in the rest pose, relative to its parent or to the world for the root.
+	Angle float64
+	// LowerAngle and UpperAngle limit the angle of the bone relative to its parent. The joint is free to rotate if they are both 0.
+	LowerAngle, UpperAngle float64
+}
+
+// RagdollOptions places a ragdoll created by NewRagdoll in the world.
+type RagdollOptions struct {
+	// Position is where the start of the root bone goes, and Angle rotates the whole ragdoll about it.
+	Position Vector
+	Angle    float64
+	// Pose holds the angle of each bone relative to its parent, overriding the rest angles in the skeleton when it's not nil.
+	Pose []float64
+
+	// Velocity and AngularVelocity set the ragdoll moving as if it was one rigid body rotating about Position.
+	Velocity        Vector
+	AngularVelocity float64
+
+	// Filter is used for the bones' shapes, with the defaults described on ShapeFilter.
+	Filter *ShapeFilter
+}
+
+// Ragdoll is a set of bodies joined together according to a skeleton. All of its slices are indexed like the skeleton.
+type Ragdoll struct {
+	Bones  []Bone
+	Bodies []*Body
+	Shapes []*Shape
+	// Joints holds the pivot joint and Limits the rotary limit joint connecting each bone to its parent.
+	// They are nil for the root bone, and Limits is nil for bones that are free to rotate.
+	Joints, Limits []*Constraint
+
+	space *Space
+}
+
+// NewRagdoll creates a ragdoll in space from a skeleton, where bones come after their parents.
+// Each bone is a capsule running along its body's x axis from its start at the body's position.
+func NewRagdoll(space *Space, skeleton []Bone, opts RagdollOptions) *Ragdoll {
+	assert(len(skeleton) > 0 && skeleton[0].Parent < 0, "The first bone must be the root")
+	assert(opts.Pose == nil || len(opts.Pose) == len(skeleton), "The pose must have an angle for every bone")
+
+	n := len(skeleton)
+	ragdoll := &Ragdoll{
+		Bones:  skeleton,
+		Bodies: make([]*Body, n),
+		Shapes: make([]*Shape, n),
+		Joints: make([]*Constraint, n),
+		Limits: make([]*Constraint, n),
+		space:  space,
+	}
+
+	filter := compositeFilter(opts.Filter)
+
+	for i, bone := range skeleton {
+		assert(bone.Length > 0 && bone.Width > 0 && bone.Mass > 0, "Bones must have a size and mass")
+		assert(bone.LowerAngle <= bone.UpperAngle, "The lower angle must not be above the upper angle")
+
+		angle := bone.Angle
+		if opts.Pose != nil {
+			angle = opts.Pose[i]
+		}
+
+		body := space.AddBody(NewBody(0, 0))
+		shape := space.AddShape(NewSegment(body, Vector{}, Vector{bone.Length, 0}, bone.Width/2))
+		shape.SetMass(bone.Mass)
+		shape.SetFilter(filter)
+
+		if i == 0 {
+			body.SetAngle(opts.Angle + angle)
+			body.SetPosition(opts.Position)
+		} else {
+			assert(bone.Parent >= 0 && bone.Parent < i, "Bones must come after their parents")
+			parent := ragdoll.Bodies[bone.Parent]
+			anchor := Vector{skeleton[bone.Parent].Length * bone.Attach, 0}
+
+			body.SetAngle(parent.Angle() + angle)
+			body.SetPosition(parent.LocalToWorld(anchor))
+
+			ragdoll.Joints[i] = space.AddConstraint(NewPivotJoint2(parent, body, anchor, Vector{}))
+			if bone.LowerAngle != 0 || bone.UpperAngle != 0 {
+				ragdoll.Limits[i] = space.AddConstraint(NewRotaryLimitJoint(parent, body, bone.LowerAngle, bone.UpperAngle))
+			}
+		}
+
+		body.SetVelocityVector(opts.Velocity.Add(body.p.Sub(opts.Position).Perp().Mult(opts.AngularVelocity)))
+		body.SetAngularVelocity(opts.AngularVelocity)
+
+		ragdoll.Bodies[i] = body
+		ragdoll.Shapes[i] = shape
+	}
+
+	return ragdoll
+}
+
+// Body returns the body of the bone with the given name, or nil if there isn't one.
+func (ragdoll *Ragdoll) Body(name string) *Body {
+	for i, bone := range ragdoll.Bones {
+		if bone.Name == name {
+			return ragdoll.Bodies[i]
+		}
+	}
+	return nil
+}
+
+// Remove removes the ragdoll's joints, shapes and bodies from the space.
+func (ragdoll *Ragdoll) Remove() {
+	for i, body := range ragdoll.Bodies {
+		if ragdoll.Joints[i] != nil {
+			ragdoll.space.RemoveConstraint(ragdoll.Joints[i])
+		}
+		if ragdoll.Limits[i] != nil {
+			ragdoll.space.RemoveConstraint(ragdoll.Limits[i])
+		}
+		ragdoll.space.RemoveShape(ragdoll.Shapes[i])
+		ragdoll.space.RemoveBody(body)
+	}
+}
+
+// HumanoidSkeleton returns a skeleton for a person of the given height and mass, standing upright and facing right.
+func HumanoidSkeleton(height, mass float64) []Bone {
+	skeleton := []Bone{
+		{Name: "torso", Parent: -1, Length: 0.3 * height, Width: 0.15 * height, Mass: 0.52 * mass, Angle: math.Pi / 2},
+		{Name: "head", Parent: 0, Attach: 1, Length: 0.13 * height, Width: 0.12 * height, Mass: 0.08 * mass,
+			LowerAngle: -math.Pi / 4, UpperAngle: math.Pi / 4},
+	}
+
+	// each limb hangs straight down from the torso and bends in the middle
+	limb := func(upper, lower Bone) {
+		skeleton = append(skeleton, upper)
+		lower.Parent = len(skeleton) - 1
+		lower.Attach = 1
+		skeleton = append(skeleton, lower)
+	}
+	for _, side := range []string{"left", "right"} {
+		limb(
+			Bone{Name: side + " upper arm", Attach: 0.95, Length: 0.17 * height, Width: 0.06 * height, Mass: 0.03 * mass,
+				Angle: math.Pi, LowerAngle: math.Pi / 4, UpperAngle: 3 * math.Pi / 2},
+			Bone{Name: side + " forearm", Length: 0.16 * height, Width: 0.05 * height, Mass: 0.02 * mass,
+				LowerAngle: 0, UpperAngle: 2.5},
+		)
+		limb(
+			Bone{Name: side + " thigh", Length: 0.24 * height, Width: 0.09 * height, Mass: 0.1 * mass,
+				Angle: math.Pi, LowerAngle: math.Pi / 2, UpperAngle: 7 * math.Pi / 6},
+			Bone{Name: side + " shin", Length: 0.24 * height, Width: 0.07 * height, Mass: 0.05 * mass,
+				LowerAngle: -2.5, UpperAngle: 0},
+		)
+	}
+	return skeleton
+}
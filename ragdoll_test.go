@@ -0,0 +1,63 @@
+package cp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRagdoll(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	space.AddShape(NewSegment(space.StaticBody, Vector{-100, 0}, Vector{100, 0}, 0)).SetFriction(1)
+
+	skeleton := HumanoidSkeleton(2, 70)
+	ragdoll := NewRagdoll(space, skeleton, RagdollOptions{
+		Position:        Vector{0, 2},
+		Velocity:        Vector{5, 0},
+		AngularVelocity: -1,
+	})
+	for i, shape := range ragdoll.Shapes {
+		shape.SetFriction(1)
+		if i > 0 && shape.Filter.Group != ragdoll.Shapes[0].Filter.Group {
+			t.Fatalf("%v isn't in the ragdoll's group", skeleton[i].Name)
+		}
+	}
+
+	head := ragdoll.Body("head")
+	if head == nil || !head.Position().Near(Vector{0, 2.6}, 1e-9) {
+		t.Fatalf("head isn't on top of the torso")
+	}
+	// the centers of the torso and head are 0.3 and 0.73 above the ragdoll's position
+	if torso := ragdoll.Body("torso"); !torso.Velocity().Near(Vector{5.3, 0}, 1e-9) || !head.Velocity().Near(Vector{5.73, 0}, 1e-9) {
+		t.Errorf("ragdoll isn't moving as one body: %v %v", torso.Velocity(), head.Velocity())
+	}
+	if math.Abs(ragdoll.Body("left shin").Position().Y-1.52) > 1e-9 {
+		t.Errorf("knee at %v", ragdoll.Body("left shin").Position())
+	}
+
+	stepSpace(space, 240)
+
+	for i, bone := range skeleton {
+		body := ragdoll.Bodies[i]
+		if body.Position().Y < -0.1 {
+			t.Errorf("%v fell through the ground", bone.Name)
+		}
+		if limit := ragdoll.Limits[i]; limit != nil {
+			angle := body.Angle() - limit.a.Angle()
+			if angle < bone.LowerAngle-0.1 || angle > bone.UpperAngle+0.1 {
+				t.Errorf("%v bent to %v, outside of its limits", bone.Name, angle)
+			}
+		}
+		if joint := ragdoll.Joints[i]; joint != nil {
+			pivot := joint.Class.(*PivotJoint)
+			if d := joint.a.LocalToWorld(pivot.AnchorA).Distance(body.Position()); d > 0.05 {
+				t.Errorf("%v came apart from its parent by %v", bone.Name, d)
+			}
+		}
+	}
+
+	ragdoll.Remove()
+	if len(space.dynamicBodies) != 0 || len(space.constraints) != 0 {
+		t.Errorf("ragdoll wasn't removed from the space")
+	}
+}
@@ -45,8 +45,7 @@ func (joint *RatchetJoint) PreStep(dt float64) {
 
 	joint.iSum = 1.0/(a.i_inv+b.i_inv)
 
-	maxBias := joint.maxBias
-	joint.bias = Clamp(-bias_coef(joint.errorBias, dt)*pdist/dt, -maxBias, maxBias)
+	joint.bias = joint.soft.bias(pdist, joint.maxBias)
 
 	if joint.bias == 0 {
 		joint.jAcc = 0
@@ -75,7 +74,7 @@ func (joint *RatchetJoint) ApplyImpulse(dt float64) {
 
 	jMax := joint.maxForce*dt
 
-	j := -(joint.bias+wr)*joint.iSum
+	j := joint.soft.impulse(joint.iSum, -(joint.bias + wr), joint.jAcc)
 	jOld := joint.jAcc
 	joint.jAcc = Clamp((jOld+j)*ratchet, 0, jMax*math.Abs(ratchet))/ratchet
 	j = joint.jAcc - jOld
@@ -87,3 +86,7 @@ func (joint *RatchetJoint) ApplyImpulse(dt float64) {
 func (joint *RatchetJoint) GetImpulse() float64 {
 	return math.Abs(joint.jAcc)
 }
+
+func (joint *RatchetJoint) reactionImpulses() Jacobian {
+	return angularImpulses(joint.jAcc)
+}
@@ -0,0 +1,164 @@
+package cp
+
+import "math"
+
+// RevoluteJoint pins two bodies together at a pivot and controls their relative rotation.
+//
+// The joint angle is the angle of b relative to a, minus ReferenceAngle. It can be limited to the range
+// [LowerAngle, UpperAngle], driven towards MotorSpeed by a motor, and pulled back towards 0 by a spring
+// when Frequency (in Hz) is set. All of these are solved together with the pivot in a single constraint.
+type RevoluteJoint struct {
+	*Constraint
+
+	AnchorA, AnchorB Vector
+	ReferenceAngle   float64
+
+	EnableLimit            bool
+	LowerAngle, UpperAngle float64
+
+	EnableMotor    bool
+	MotorSpeed     float64
+	MaxMotorTorque float64
+
+	Frequency, DampingRatio float64
+
+	r1, r2 Vector
+	k      Mat2x2
+	bias   Vector
+
+	angle, angleMass     float64
+	lowerBias, upperBias float64
+	springSoft           softness
+
+	jAcc                                        Vector
+	springJAcc, motorJAcc, lowerJAcc, upperJAcc float64
+}
+
+// NewRevoluteJoint creates a revolute joint at a pivot in world coordinates, keeping the current relative angle of the bodies.
+func NewRevoluteJoint(a, b *Body, pivot Vector) *Constraint {
+	return NewRevoluteJoint2(a, b, a.WorldToLocal(pivot), b.WorldToLocal(pivot), b.a-a.a)
+}
+
+// NewRevoluteJoint2 creates a revolute joint with anchors in body local coordinates and the relative angle at which the joint angle is 0.
+func NewRevoluteJoint2(a, b *Body, anchorA, anchorB Vector, referenceAngle float64) *Constraint {
+	joint := &RevoluteJoint{
+		AnchorA:        anchorA,
+		AnchorB:        anchorB,
+		ReferenceAngle: referenceAngle,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *RevoluteJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r1 = a.transform.Vect(joint.AnchorA.Sub(a.cog))
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+	joint.k = k_tensor(a, b, joint.r1, joint.r2)
+
+	delta := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
+	joint.bias = joint.soft.biasVect(delta, joint.maxBias)
+
+	joint.angle = b.a - a.a - joint.ReferenceAngle
+	// the spring, motor and limits do nothing when neither body can rotate
+	joint.angleMass = 0
+	if !fixedRotation(a, b) {
+		joint.angleMass = 1 / (a.i_inv + b.i_inv)
+	}
+
+	joint.springSoft = newSoftness(joint.Frequency, joint.DampingRatio, dt)
+	if joint.Frequency <= 0 {
+		joint.springJAcc = 0
+	}
+	if !joint.EnableMotor {
+		joint.motorJAcc = 0
+	}
+	if joint.EnableLimit {
+		joint.lowerBias = joint.soft.limitBias(joint.angle-joint.LowerAngle, dt, joint.maxBias)
+		joint.upperBias = joint.soft.limitBias(joint.UpperAngle-joint.angle, dt, joint.maxBias)
+	} else {
+		joint.lowerJAcc = 0
+		joint.upperJAcc = 0
+	}
+}
+
+func (joint *RevoluteJoint) applyAngularImpulse(j float64) {
+	joint.a.w -= j * joint.a.i_inv
+	joint.b.w += j * joint.b.i_inv
+}
+
+func (joint *RevoluteJoint) ApplyCachedImpulse(dt_coef float64) {
+	apply_impulses(joint.a, joint.b, joint.r1, joint.r2, joint.jAcc.Mult(dt_coef))
+	joint.applyAngularImpulse(joint.angularImpulse() * dt_coef)
+}
+
+// angularImpulse returns the total impulse applied by the spring, motor and limits.
+func (joint *RevoluteJoint) angularImpulse() float64 {
+	return joint.springJAcc + joint.motorJAcc + joint.lowerJAcc - joint.upperJAcc
+}
+
+func (joint *RevoluteJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	if joint.Frequency > 0 {
+		soft := joint.springSoft
+		wr := b.w - a.w
+		j := soft.impulse(joint.angleMass, -soft.biasRate*joint.angle-wr, joint.springJAcc)
+		joint.springJAcc += j
+		joint.applyAngularImpulse(j)
+	}
+
+	if joint.EnableMotor {
+		wr := b.w - a.w
+		jMax := joint.MaxMotorTorque * dt
+		jOld := joint.motorJAcc
+		joint.motorJAcc = Clamp(jOld+(joint.MotorSpeed-wr)*joint.angleMass, -jMax, jMax)
+		joint.applyAngularImpulse(joint.motorJAcc - jOld)
+	}
+
+	if joint.EnableLimit {
+		wr := b.w - a.w
+		jOld := joint.lowerJAcc
+		joint.lowerJAcc = max(jOld+joint.soft.impulse(joint.angleMass, joint.lowerBias-wr, jOld), 0)
+		joint.applyAngularImpulse(joint.lowerJAcc - jOld)
+
+		// the upper limit pushes the other way
+		wr = a.w - b.w
+		jOld = joint.upperJAcc
+		joint.upperJAcc = max(jOld+joint.soft.impulse(joint.angleMass, joint.upperBias-wr, jOld), 0)
+		joint.applyAngularImpulse(jOld - joint.upperJAcc)
+	}
+
+	vr := relative_velocity(a, b, joint.r1, joint.r2)
+	j := joint.soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
+	jOld := joint.jAcc
+	joint.jAcc = jOld.Add(j).Clamp(joint.maxForce * dt)
+	apply_impulses(a, b, joint.r1, joint.r2, joint.jAcc.Sub(jOld))
+}
+
+func (joint *RevoluteJoint) GetImpulse() float64 {
+	j := joint.angularImpulse()
+	return math.Sqrt(joint.jAcc.LengthSq() + j*j)
+}
+
+// Angle returns the current joint angle.
+func (joint *RevoluteJoint) Angle() float64 {
+	return joint.b.a - joint.a.a - joint.ReferenceAngle
+}
+
+// Speed returns the current angular velocity of b relative to a.
+func (joint *RevoluteJoint) Speed() float64 {
+	return joint.b.w - joint.a.w
+}
+
+// MotorTorque returns the torque the motor applied to b during the last step.
+func (joint *RevoluteJoint) MotorTorque() float64 {
+	return joint.impulseToForce(joint.motorJAcc)
+}
+
+func (joint *RevoluteJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.jAcc).add(angularImpulses(joint.angularImpulse()))
+}
@@ -0,0 +1,148 @@
+package cp
+
+import "math"
+
+// RopeOptions describes a rope or chain created by NewRope.
+type RopeOptions struct {
+	// Segments is the number of links in the rope.
+	Segments int
+	// Length is the total length of the rope. The distance between the two anchors is used if it's 0.
+	Length float64
+	// Thickness is the diameter of the links.
+	Thickness float64
+	// MassPerLength is the mass of each link divided by its length.
+	// Like any chain of joints, a rope is most stable when its links aren't much lighter than what hangs from it.
+	MassPerLength float64
+	// Filter is used for the links' shapes, with the defaults described on ShapeFilter.
+	Filter *ShapeFilter
+}
+
+// Rope is a chain of capsule shaped links joined end to end by pivots, hanging between two anchors.
+type Rope struct {
+	Bodies []*Body
+	Shapes []*Shape
+	// Joints are the pivots from body a through each of the links to body b, so there is one more joint than there are links.
+	Joints []*Constraint
+
+	space         *Space
+	length        float64
+	massPerLength float64
+}
+
+// NewRope creates a rope in space hanging from anchorA on a to anchorB on b.
+// A nil body attaches that end of the rope to the space's static body, in which case the anchor is in world coordinates.
+// If the rope is longer than the distance between the anchors, it starts out sagging below them.
+func NewRope(space *Space, a, b *Body, anchorA, anchorB Vector, opts RopeOptions) *Rope {
+	assert(opts.Segments > 0, "A rope needs at least one segment")
+	assert(opts.MassPerLength > 0, "Mass per length must be positive")
+
+	if a == nil {
+		a = space.StaticBody
+	}
+	if b == nil {
+		b = space.StaticBody
+	}
+
+	rope := &Rope{
+		space:         space,
+		massPerLength: opts.MassPerLength,
+	}
+
+	start := a.transform.Point(anchorA)
+	end := b.transform.Point(anchorB)
+	dist := start.Distance(end)
+	rope.length = opts.Length
+	if rope.length == 0 {
+		rope.length = dist
+	}
+	assert(rope.length > 0, "Length must be positive")
+
+	// lay the rope out along two straight legs meeting below the middle of the anchors
+	down := Vector{0, -1}
+	if dist > 0 {
+		down = end.Sub(start).Normalize().ReversePerp()
+	}
+	leg := max(rope.length, dist) / 2
+	middle := start.Lerp(end, 0.5).Add(down.Mult(math.Sqrt(max(leg*leg-dist*dist/4, 0))))
+	pointAt := func(t float64) Vector {
+		if t <= 0.5 {
+			return start.Lerp(middle, 2*t)
+		}
+		return middle.Lerp(end, 2*t-1)
+	}
+
+	filter := compositeFilter(opts.Filter)
+
+	n := opts.Segments
+	half := rope.length / float64(n) / 2
+	prev, prevAnchor := a, anchorA
+	for i := range n {
+		p1 := pointAt(float64(i) / float64(n))
+		p2 := pointAt(float64(i+1) / float64(n))
+
+		body := space.AddBody(NewBody(0, 0))
+		body.SetAngle(p2.Sub(p1).ToAngle())
+		body.SetPosition(p1.Lerp(p2, 0.5))
+
+		shape := space.AddShape(NewSegment(body, Vector{-half, 0}, Vector{half, 0}, opts.Thickness/2))
+		shape.SetMass(opts.MassPerLength * 2 * half)
+		shape.SetFilter(filter)
+
+		rope.Bodies = append(rope.Bodies, body)
+		rope.Shapes = append(rope.Shapes, shape)
+		rope.addJoint(prev, body, prevAnchor, Vector{-half, 0})
+		prev, prevAnchor = body, Vector{half, 0}
+	}
+	rope.addJoint(prev, b, prevAnchor, anchorB)
+
+	return rope
+}
+
+func (rope *Rope) addJoint(a, b *Body, anchorA, anchorB Vector) {
+	joint := rope.space.AddConstraint(NewPivotJoint2(a, b, anchorA, anchorB))
+	joint.SetCollideBodies(false)
+	rope.Joints = append(rope.Joints, joint)
+}
+
+func (rope *Rope) Length() float64 {
+	return rope.length
+}
+
+// SetLength winches the rope in or out by resizing all of its links, which keep the same mass per length.
+// The joints pull the links back together over the following steps, so large changes should be spread over several steps.
+func (rope *Rope) SetLength(length float64) {
+	assert(length > 0, "Length must be positive")
+	rope.length = length
+
+	half := length / float64(len(rope.Bodies)) / 2
+	for i, shape := range rope.Shapes {
+		shape.Class.(*Segment).SetEndpoints(Vector{-half, 0}, Vector{half, 0})
+		shape.SetMass(rope.massPerLength * 2 * half)
+
+		rope.Joints[i].Class.(*PivotJoint).AnchorB = Vector{-half, 0}
+		rope.Joints[i+1].Class.(*PivotJoint).AnchorA = Vector{half, 0}
+	}
+	for _, joint := range rope.Joints {
+		joint.ActivateBodies()
+	}
+}
+
+// Points returns the positions of the joints in world coordinates, from anchor a to anchor b.
+func (rope *Rope) Points() []Vector {
+	points := make([]Vector, len(rope.Joints))
+	for i, joint := range rope.Joints {
+		points[i] = joint.b.transform.Point(joint.Class.(*PivotJoint).AnchorB)
+	}
+	return points
+}
+
+// Remove removes the rope's joints, shapes and bodies from the space.
+func (rope *Rope) Remove() {
+	for _, joint := range rope.Joints {
+		rope.space.RemoveConstraint(joint)
+	}
+	for i, body := range rope.Bodies {
+		rope.space.RemoveShape(rope.Shapes[i])
+		rope.space.RemoveBody(body)
+	}
+}
@@ -0,0 +1,97 @@
+package cp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRope(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	space.Iterations = 30
+
+	weight := space.AddBody(NewBody(1, MomentForCircle(1, 0, 1, Vector{})))
+	weight.SetPosition(Vector{0, -10})
+
+	rope := NewRope(space, nil, weight, Vector{}, Vector{}, RopeOptions{
+		Segments:      10,
+		Thickness:     0.2,
+		MassPerLength: 1,
+	})
+	if len(rope.Bodies) != 10 || len(rope.Joints) != 11 {
+		t.Fatalf("got %v links and %v joints", len(rope.Bodies), len(rope.Joints))
+	}
+	if group := rope.Shapes[0].Filter.Group; group == NO_GROUP || rope.Shapes[9].Filter.Group != group {
+		t.Errorf("expected the links to share a group so they don't collide with each other")
+	}
+	if filter := rope.Shapes[0].Filter; filter.Categories != ALL_CATEGORIES || filter.Mask != ALL_CATEGORIES {
+		t.Errorf("expected the links to collide with everything else, got %v", filter)
+	}
+	if rope.Length() != 10 {
+		t.Errorf("expected the rope to span the anchors, got length %v", rope.Length())
+	}
+
+	stepSpace(space, 120)
+	if y := weight.Position().Y; math.Abs(y+10) > 0.2 {
+		t.Errorf("weight hanging at %v, want -10", y)
+	}
+
+	// winch it in over a couple of seconds
+	for rope.Length() > 5 {
+		rope.SetLength(max(rope.Length()-0.05, 5))
+		stepSpace(space, 1)
+	}
+	stepSpace(space, 120)
+	if y := weight.Position().Y; math.Abs(y+5) > 0.2 {
+		t.Errorf("weight winched up to %v, want -5", y)
+	}
+
+	points := rope.Points()
+	if !points[0].Near(Vector{}, 0.1) || !points[len(points)-1].Near(weight.Position(), 0.1) {
+		t.Errorf("rope points don't start and end at the anchors: %v", points)
+	}
+
+	rope.Remove()
+	if len(space.dynamicBodies) != 1 || len(space.constraints) != 0 {
+		t.Errorf("rope wasn't removed from the space")
+	}
+}
+
+func TestRope_Sag(t *testing.T) {
+	space := NewSpace()
+	rope := NewRope(space, nil, nil, Vector{-5, 0}, Vector{5, 0}, RopeOptions{
+		Segments:      8,
+		Length:        20,
+		Thickness:     0.2,
+		MassPerLength: 1,
+	})
+
+	// a slack rope is laid out hanging down between the anchors
+	low := rope.Points()[4]
+	if !low.Near(Vector{0, -math.Sqrt(75)}, 0.01) {
+		t.Errorf("middle of the rope at %v", low)
+	}
+
+	// every rope gets a group of its own so different ropes still collide
+	other := NewRope(space, nil, nil, Vector{-5, 5}, Vector{5, 5}, RopeOptions{
+		Segments:      8,
+		Thickness:     0.2,
+		MassPerLength: 1,
+	})
+	if rope.Shapes[0].Filter.Group == other.Shapes[0].Filter.Group {
+		t.Errorf("expected two ropes to have different groups")
+	}
+
+	// collisions can be turned off entirely
+	ghost := NewRope(space, nil, nil, Vector{-5, 10}, Vector{5, 10}, RopeOptions{
+		Segments:      8,
+		Thickness:     0.2,
+		MassPerLength: 1,
+		Filter:        &SHAPE_FILTER_NONE,
+	})
+	for _, shape := range ghost.Shapes {
+		if !shape.Filter.Reject(SHAPE_FILTER_ALL) {
+			t.Errorf("expected the rope not to collide, got %v", shape.Filter)
+		}
+	}
+}
@@ -33,8 +33,7 @@ func (joint *RotaryLimitJoint) PreStep(dt float64) {
 
 	joint.iSum = 1.0/(a.i_inv + b.i_inv)
 
-	maxBias := joint.maxBias
-	joint.bias = Clamp(-bias_coef(joint.errorBias, dt)*pdist/dt, -maxBias, maxBias)
+	joint.bias = joint.soft.bias(pdist, joint.maxBias)
 
 	if joint.bias == 0 {
 		joint.jAcc = 0
@@ -62,7 +61,7 @@ func (joint *RotaryLimitJoint) ApplyImpulse(dt float64) {
 
 	jMax := joint.maxForce*dt
 
-	j := -(joint.bias + wr)*joint.iSum
+	j := joint.soft.impulse(joint.iSum, -(joint.bias + wr), joint.jAcc)
 	jOld := joint.jAcc
 	if joint.bias < 0 {
 		joint.jAcc = Clamp(jOld + j, 0, jMax)
@@ -79,3 +78,6 @@ func (joint *RotaryLimitJoint) GetImpulse() float64 {
 	return math.Abs(joint.jAcc)
 }
 
+func (joint *RotaryLimitJoint) reactionImpulses() Jacobian {
+	return angularImpulses(joint.jAcc)
+}
@@ -0,0 +1,88 @@
+package cp
+
+import "math"
+
+// ServoJoint turns b to a target angle relative to a, like a hobby servo or a turret motor.
+//
+// It behaves like a PD controller with the gains Stiffness (torque per radian of error) and Damping (torque per
+// radian per second), but is solved implicitly so high gains stay stable. If both gains are 0 the servo holds the
+// target rigidly, or as set with SetSoftness. It never turns faster than MaxSpeed or applies more than MaxTorque.
+type ServoJoint struct {
+	*Constraint
+
+	TargetAngle         float64
+	MaxTorque, MaxSpeed float64
+	Stiffness, Damping  float64
+
+	mass, gamma float64
+	bias, jAcc  float64
+}
+
+// NewServoJoint creates a servo that turns b to targetAngle relative to a.
+func NewServoJoint(a, b *Body, targetAngle, maxTorque, maxSpeed float64) *Constraint {
+	joint := &ServoJoint{
+		TargetAngle: targetAngle,
+		MaxTorque:   maxTorque,
+		MaxSpeed:    maxSpeed,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+// Angle returns the current angle of b relative to a.
+func (joint *ServoJoint) Angle() float64 {
+	return joint.b.a - joint.a.a
+}
+
+func (joint *ServoJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	c := joint.Angle() - joint.TargetAngle
+	maxSpeed := math.Min(joint.MaxSpeed, joint.maxBias)
+	iSum := a.i_inv + b.i_inv
+
+	if denom := joint.Damping + dt*joint.Stiffness; denom > 0 && iSum > 0 {
+		// the soft constraint equivalent to a spring and damper with these gains
+		joint.gamma = 1 / (dt * denom)
+		joint.mass = 1 / (iSum + joint.gamma)
+		joint.bias = Clamp(-joint.Stiffness/denom*c, -maxSpeed, maxSpeed)
+		return
+	}
+
+	// the same as softness.impulse, written in terms of mass and gamma
+	joint.mass, joint.gamma = 0, 0
+	if iSum > 0 {
+		joint.mass = joint.soft.massScale / iSum
+		joint.gamma = joint.soft.impulseScale / joint.mass
+	}
+	joint.bias = joint.soft.bias(c, maxSpeed)
+}
+
+func (joint *ServoJoint) ApplyCachedImpulse(dt_coef float64) {
+	j := joint.jAcc * dt_coef
+	joint.a.w -= j * joint.a.i_inv
+	joint.b.w += j * joint.b.i_inv
+}
+
+func (joint *ServoJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	wr := b.w - a.w
+	jMax := joint.MaxTorque * dt
+	jOld := joint.jAcc
+	joint.jAcc = Clamp(jOld+joint.mass*(joint.bias-wr-joint.gamma*jOld), -jMax, jMax)
+	j := joint.jAcc - jOld
+
+	a.w -= j * a.i_inv
+	b.w += j * b.i_inv
+}
+
+func (joint *ServoJoint) GetImpulse() float64 {
+	return math.Abs(joint.jAcc)
+}
+
+func (joint *ServoJoint) reactionImpulses() Jacobian {
+	return angularImpulses(joint.jAcc)
+}
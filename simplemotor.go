@@ -55,3 +55,7 @@ func (motor *SimpleMotor) ApplyImpulse(dt float64) {
 func (motor *SimpleMotor) GetImpulse() float64 {
 	return math.Abs(motor.jAcc)
 }
+
+func (motor *SimpleMotor) reactionImpulses() Jacobian {
+	return angularImpulses(motor.jAcc)
+}
@@ -51,8 +51,7 @@ func (joint *SlideJoint) PreStep(dt float64) {
 	joint.nMass = 1.0 / k_scalar(a, b, joint.r1, joint.r2, joint.n)
 
 	// calculate bias velocity
-	maxBias := joint.maxBias
-	joint.bias = Clamp(-bias_coef(joint.errorBias, dt)*pdist/dt, -maxBias, maxBias)
+	joint.bias = joint.soft.bias(pdist, joint.maxBias)
 }
 
 func (joint *SlideJoint) ApplyCachedImpulse(dt_coef float64) {
@@ -77,7 +76,7 @@ func (joint *SlideJoint) ApplyImpulse(dt float64) {
 	vr := relative_velocity(a, b, r1, r2)
 	vrn := vr.Dot(n)
 
-	jn := (joint.bias - vrn) * joint.nMass
+	jn := joint.soft.impulse(joint.nMass, joint.bias-vrn, joint.jnAcc)
 	jnOld := joint.jnAcc
 	joint.jnAcc = Clamp(jnOld+jn, -joint.maxForce*dt, 0)
 	jn = joint.jnAcc - jnOld
@@ -88,3 +87,7 @@ func (joint *SlideJoint) ApplyImpulse(dt float64) {
 func (joint *SlideJoint) GetImpulse() float64 {
 	return math.Abs(joint.jnAcc)
 }
+
+func (joint *SlideJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.n.Mult(joint.jnAcc))
+}
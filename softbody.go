@@ -0,0 +1,220 @@
+package cp
+
+import "math"
+
+// SoftBodyOptions describes the particles and springs of a soft body.
+type SoftBodyOptions struct {
+	ParticleRadius, ParticleMass float64
+	// Stiffness and Damping are used for all of the springs between the particles.
+	Stiffness, Damping float64
+	// Pressure pushes the outline outwards when it's squashed smaller than its rest area and pulls it in when it's stretched larger.
+	// It's the force per unit length of the outline when the area is half its rest area. Zero disables it.
+	Pressure float64
+
+	// Filter is used for the particles' shapes, with the defaults described on ShapeFilter.
+	Filter *ShapeFilter
+}
+
+// SoftBody is a deformable body made of small circular particles held together by damped springs.
+type SoftBody struct {
+	Bodies  []*Body
+	Shapes  []*Shape
+	Springs []*Constraint
+
+	// Pressure and RestArea can be changed at any time, for example to inflate or deflate the soft body.
+	Pressure, RestArea float64
+
+	space *Space
+	opts  SoftBodyOptions
+	// indexes of the particles around the outside in counter-clockwise order
+	outline  []int
+	pressure *Constraint
+}
+
+// NewSoftBodyRing creates a ring of count particles around center that keeps its shape using the springs and Pressure.
+// Every particle is joined to its two neighbours on either side.
+func NewSoftBodyRing(space *Space, center Vector, radius float64, count int, opts SoftBodyOptions) *SoftBody {
+	assert(count >= 3, "A ring needs at least three particles")
+
+	soft := newSoftBody(space, opts)
+	for i := range count {
+		soft.addParticle(center.Add(ForAngle(2 * math.Pi * float64(i) / float64(count)).Mult(radius)))
+		soft.outline = append(soft.outline, i)
+	}
+	for i := range count {
+		soft.addSpring(i, (i+1)%count)
+		if count > 4 {
+			soft.addSpring(i, (i+2)%count)
+		}
+	}
+
+	soft.finish()
+	return soft
+}
+
+// NewSoftBodyGrid creates a block of columns by rows particles filling bb.
+// Neighbouring particles are joined along the rows, the columns and both diagonals.
+func NewSoftBodyGrid(space *Space, bb BB, columns, rows int, opts SoftBodyOptions) *SoftBody {
+	assert(columns >= 2 && rows >= 2, "A grid needs at least two columns and rows")
+
+	soft := newSoftBody(space, opts)
+	index := func(x, y int) int {
+		return y*columns + x
+	}
+	for y := range rows {
+		for x := range columns {
+			soft.addParticle(Vector{
+				Lerp(bb.L, bb.R, float64(x)/float64(columns-1)),
+				Lerp(bb.B, bb.T, float64(y)/float64(rows-1)),
+			})
+		}
+	}
+	for y := range rows {
+		for x := range columns {
+			if x+1 < columns {
+				soft.addSpring(index(x, y), index(x+1, y))
+			}
+			if y+1 < rows {
+				soft.addSpring(index(x, y), index(x, y+1))
+			}
+			if x+1 < columns && y+1 < rows {
+				soft.addSpring(index(x, y), index(x+1, y+1))
+				soft.addSpring(index(x+1, y), index(x, y+1))
+			}
+		}
+	}
+
+	// walk around the edge counter-clockwise starting from the bottom left corner
+	for x := 0; x < columns-1; x++ {
+		soft.outline = append(soft.outline, index(x, 0))
+	}
+	for y := 0; y < rows-1; y++ {
+		soft.outline = append(soft.outline, index(columns-1, y))
+	}
+	for x := columns - 1; x > 0; x-- {
+		soft.outline = append(soft.outline, index(x, rows-1))
+	}
+	for y := rows - 1; y > 0; y-- {
+		soft.outline = append(soft.outline, index(0, y))
+	}
+
+	soft.finish()
+	return soft
+}
+
+func newSoftBody(space *Space, opts SoftBodyOptions) *SoftBody {
+	assert(opts.ParticleRadius > 0 && opts.ParticleMass > 0, "Particles must have a size and mass")
+
+	soft := &SoftBody{
+		Pressure: opts.Pressure,
+		space:    space,
+	}
+
+	filter := compositeFilter(opts.Filter)
+	opts.Filter = &filter
+	soft.opts = opts
+	return soft
+}
+
+func (soft *SoftBody) addParticle(pos Vector) {
+	opts := soft.opts
+	body := soft.space.AddBody(NewBody(opts.ParticleMass, MomentForCircle(opts.ParticleMass, 0, opts.ParticleRadius, Vector{})))
+	body.SetPosition(pos)
+	shape := soft.space.AddShape(NewCircle(body, opts.ParticleRadius, Vector{}))
+	shape.SetFilter(*opts.Filter)
+
+	soft.Bodies = append(soft.Bodies, body)
+	soft.Shapes = append(soft.Shapes, shape)
+}
+
+func (soft *SoftBody) addSpring(i, j int) {
+	opts := soft.opts
+	a, b := soft.Bodies[i], soft.Bodies[j]
+
+	spring := soft.space.AddConstraint(NewDampedSpring(a, b, Vector{}, Vector{}, a.Position().Distance(b.Position()), opts.Stiffness, opts.Damping))
+	spring.SetCollideBodies(false)
+	soft.Springs = append(soft.Springs, spring)
+}
+
+func (soft *SoftBody) finish() {
+	soft.RestArea = soft.Area()
+
+	// the pressure is solved along with the springs, hooked up to two particles so it sleeps and wakes with the rest of them
+	pressure := &softBodyPressure{soft: soft}
+	pressure.Constraint = NewConstraint(pressure, soft.Bodies[soft.outline[0]], soft.Bodies[soft.outline[1]])
+	soft.pressure = soft.space.AddConstraint(pressure.Constraint)
+}
+
+// Area returns the area currently enclosed by the centers of the particles around the outline.
+func (soft *SoftBody) Area() float64 {
+	verts := soft.outlineVerts()
+	return AreaForPoly(len(verts), verts, 0)
+}
+
+func (soft *SoftBody) outlineVerts() []Vector {
+	verts := make([]Vector, len(soft.outline))
+	for i, index := range soft.outline {
+		verts[i] = soft.Bodies[index].Position()
+	}
+	return verts
+}
+
+// Outline returns the centers of the particles around the outside of the soft body as a closed, counter-clockwise PolyLine.
+// Render it offset outwards by the particle radius to cover the particles.
+func (soft *SoftBody) Outline() *PolyLine {
+	verts := soft.outlineVerts()
+	return &PolyLine{Verts: append(verts, verts[0])}
+}
+
+// Remove removes the soft body's springs, shapes and bodies from the space.
+func (soft *SoftBody) Remove() {
+	soft.space.RemoveConstraint(soft.pressure)
+	for _, spring := range soft.Springs {
+		soft.space.RemoveConstraint(spring)
+	}
+	for i, body := range soft.Bodies {
+		soft.space.RemoveShape(soft.Shapes[i])
+		soft.space.RemoveBody(body)
+	}
+}
+
+// softBodyPressure applies the pressure of a soft body to the particles around its outline.
+// Like DampedSpring, it applies all of its impulse in PreStep.
+type softBodyPressure struct {
+	*Constraint
+	soft *SoftBody
+
+	jAcc float64
+}
+
+func (pressure *softBodyPressure) PreStep(dt float64) {
+	soft := pressure.soft
+	pressure.jAcc = 0
+	if soft.Pressure == 0 || soft.RestArea <= 0 {
+		return
+	}
+
+	// the force per unit length, like an ideal gas it grows without bound as the area shrinks
+	area := max(soft.Area(), soft.RestArea*1e-3)
+	force := soft.Pressure * (soft.RestArea/area - 1)
+
+	count := len(soft.outline)
+	for i := range count {
+		a := soft.Bodies[soft.outline[i]]
+		b := soft.Bodies[soft.outline[(i+1)%count]]
+
+		// the outward normal of a counter-clockwise edge, scaled by its length
+		j := b.p.Sub(a.p).ReversePerp().Mult(force * dt / 2)
+		a.v = a.v.Add(j.Mult(a.m_inv))
+		b.v = b.v.Add(j.Mult(b.m_inv))
+		pressure.jAcc += 2 * j.Length()
+	}
+}
+
+func (pressure *softBodyPressure) ApplyCachedImpulse(dt_coef float64) {}
+
+func (pressure *softBodyPressure) ApplyImpulse(dt float64) {}
+
+func (pressure *softBodyPressure) GetImpulse() float64 {
+	return pressure.jAcc
+}
@@ -0,0 +1,63 @@
+package cp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSoftBodyRing(t *testing.T) {
+	squash := func(pressure float64) float64 {
+		space := NewSpace()
+		space.SetGravity(Vector{0, -100})
+		space.Iterations = 20
+		space.AddShape(NewSegment(space.StaticBody, Vector{-20, 0}, Vector{20, 0}, 0))
+
+		soft := NewSoftBodyRing(space, Vector{0, 6}, 5, 20, SoftBodyOptions{
+			ParticleRadius: 0.5,
+			ParticleMass:   1,
+			Stiffness:      50,
+			Damping:        2,
+			Pressure:       pressure,
+		})
+		if got, want := soft.Area(), 20*25*math.Sin(2*math.Pi/20)/2; math.Abs(got-want) > 1e-6 {
+			t.Fatalf("area is %v, want %v", got, want)
+		}
+
+		stepSpace(space, 300)
+		return soft.Area() / soft.RestArea
+	}
+
+	flat, inflated := squash(0), squash(500)
+	if inflated < 0.8 || inflated < flat+0.1 {
+		t.Errorf("pressure kept %v of the area, without it %v", inflated, flat)
+	}
+}
+
+func TestSoftBodyGrid(t *testing.T) {
+	space := NewSpace()
+	soft := NewSoftBodyGrid(space, BB{0, 0, 4, 3}, 5, 4, SoftBodyOptions{
+		ParticleRadius: 0.25,
+		ParticleMass:   1,
+		Stiffness:      100,
+		Damping:        1,
+	})
+	if len(soft.Bodies) != 20 || len(soft.Springs) != 4*4+5*3+2*4*3 {
+		t.Fatalf("got %v particles and %v springs", len(soft.Bodies), len(soft.Springs))
+	}
+
+	outline := soft.Outline()
+	if !outline.IsClosed() || len(outline.Verts) != 15 {
+		t.Fatalf("expected a closed outline around 14 particles, got %v", outline.Verts)
+	}
+	if outline.Verts[4] != (Vector{4, 0}) || outline.Verts[7] != (Vector{4, 3}) {
+		t.Errorf("outline isn't counter-clockwise: %v", outline.Verts)
+	}
+	if soft.RestArea != 12 {
+		t.Errorf("rest area is %v", soft.RestArea)
+	}
+
+	soft.Remove()
+	if len(space.dynamicBodies) != 0 || len(space.constraints) != 0 {
+		t.Errorf("soft body wasn't removed from the space")
+	}
+}
@@ -1,6 +1,8 @@
 package cp
 
 import (
+	"container/heap"
+	"errors"
 	"math"
 	"slices"
 	"sync"
@@ -53,6 +55,8 @@ type Space struct {
 	skipPostStep      bool
 	postStepCallbacks []*PostStepCallback
 
+	constraintBreakFunc ConstraintBreakFunc
+
 	StaticBody *Body
 }
 
@@ -256,6 +260,18 @@ func Contains(bodies []*Body, body *Body) bool {
 }
 
 func (space *Space) AddShape(shape *Shape) *Shape {
+	if space.attachShape(shape) {
+		space.staticShapes.class.Insert(shape, shape.HashId())
+	} else {
+		space.dynamicShapes.class.Insert(shape, shape.HashId())
+	}
+
+	return shape
+}
+
+// attachShape does the work of AddShape except for inserting the shape into a spatial index.
+// Returns true if the shape belongs in the static index.
+func (space *Space) attachShape(shape *Shape) bool {
 	var body *Body = shape.Body()
 
 	assert(shape.space != space, "You have already added this shape to this space. You must not add it a second time.")
@@ -271,15 +287,60 @@ func (space *Space) AddShape(shape *Shape) *Shape {
 	shape.SetHashId(HashValue(space.shapeIDCounter))
 	space.shapeIDCounter += 1
 	shape.Update(body.transform)
+	shape.SetSpace(space)
 
-	if isStatic {
-		space.staticShapes.class.Insert(shape, shape.HashId())
+	return isStatic
+}
+
+// AddShapes adds many shapes at once.
+//
+// Static shapes are bulk loaded into the static index in a single pass when it is a BBTree,
+// which is much faster than adding them one at a time when loading large levels.
+func (space *Space) AddShapes(shapes ...*Shape) {
+	var static []*Shape
+	for _, shape := range shapes {
+		if space.attachShape(shape) {
+			static = append(static, shape)
+		} else {
+			space.dynamicShapes.class.Insert(shape, shape.HashId())
+		}
+	}
+
+	if tree := space.staticShapes.GetTree(); tree != nil {
+		tree.BulkInsert(static)
 	} else {
-		space.dynamicShapes.class.Insert(shape, shape.HashId())
+		for _, shape := range static {
+			space.staticShapes.class.Insert(shape, shape.HashId())
+		}
 	}
-	shape.SetSpace(space)
+}
 
-	return shape
+// BakeStaticShapes saves the layout of the static BBTree so a level can be reloaded with AddBakedShapes
+// without building the tree again. shapes must hold every static shape in the space.
+//
+// Shapes of sleeping bodies are kept in the static index, so bake a level before stepping the space.
+func (space *Space) BakeStaticShapes(shapes []*Shape) (*BBTreeBake, error) {
+	tree := space.staticShapes.GetTree()
+	if tree == nil {
+		return nil, errors.New("cp: only a BBTree static index can be baked")
+	}
+	return tree.Bake(shapes)
+}
+
+// AddBakedShapes adds static shapes to the space using a layout saved by BakeStaticShapes.
+// shapes must be in the same order as when they were baked.
+func (space *Space) AddBakedShapes(bake *BBTreeBake, shapes ...*Shape) {
+	tree := space.staticShapes.GetTree()
+	assert(tree != nil, "Baked shapes can only be added to a BBTree static index")
+	assert(len(shapes) == bake.Count(), "The number of shapes does not match the bake")
+	for _, shape := range shapes {
+		assert(shape.body.GetType() == BODY_STATIC, "Only static shapes can be baked")
+	}
+
+	for _, shape := range shapes {
+		space.attachShape(shape)
+	}
+	tree.InsertBake(bake, shapes)
 }
 
 func (space *Space) AddBody(body *Body) *Body {
@@ -719,6 +780,7 @@ func (space *Space) Step(dt float64) {
 				constraint.PreSolve(constraint, space)
 			}
 
+			constraint.prepareSoftness(dt)
 			constraint.Class.PreStep(dt)
 		}
 
@@ -762,6 +824,15 @@ func (space *Space) Step(dt float64) {
 			}
 		}
 
+		// Remove the constraints that broke once the step is done
+		for _, constraint := range space.constraints {
+			// a damped spring reports a negative impulse when it pulls
+			impulse := math.Abs(constraint.Class.GetImpulse())
+			if constraint.checkBreak(impulse, dt) {
+				space.AddPostStepCallback(breakConstraint, constraint, impulse)
+			}
+		}
+
 		// run the post-solve callbacks
 		for _, arb := range space.arbiters {
 			arb.handler.PostSolveFunc(arb, space, arb.handler.UserData)
@@ -770,6 +841,23 @@ func (space *Space) Step(dt float64) {
 	space.Unlock(true)
 }
 
+// SetConstraintBreakFunc sets the function called when a breakable constraint breaks and is removed from the space.
+func (space *Space) SetConstraintBreakFunc(f ConstraintBreakFunc) {
+	space.constraintBreakFunc = f
+}
+
+func breakConstraint(space *Space, key, data any) {
+	constraint := key.(*Constraint)
+	if !space.ContainsConstraint(constraint) {
+		return
+	}
+
+	space.RemoveConstraint(constraint)
+	if space.constraintBreakFunc != nil {
+		space.constraintBreakFunc(constraint, space, data.(float64))
+	}
+}
+
 func (space *Space) Lock() {
 	space.locked++
 }
@@ -867,17 +955,51 @@ func (space *Space) UseWildcardDefaultHandler() {
 	}
 }
 
+// UseSpatialHash switches the space to use a spatial hash for both the static and dynamic shapes.
 func (space *Space) UseSpatialHash(dim float64, count int) {
-	staticShapes := NewSpaceHash(dim, count, ShapeGetBB, nil)
-	dynamicShapes := NewSpaceHash(dim, count, ShapeGetBB, staticShapes)
+	spatialHash := func(bbfunc SpatialIndexBB, staticIndex *SpatialIndex) *SpatialIndex {
+		return NewSpaceHash(dim, count, bbfunc, staticIndex)
+	}
+	space.UseSpatialIndex(spatialHash, spatialHash)
+}
 
-	space.staticShapes.class.Each(func(shape *Shape) {
-		staticShapes.class.Insert(shape, shape.hashid)
-	})
-	space.dynamicShapes.class.Each(func(shape *Shape) {
+// UseSpatialIndex moves the shapes of the space into new spatial indexes.
+//
+// The dynamic index is always rebuilt with dynamic, the static index is only replaced when static is not nil.
+// This may be called at any time outside of Step and queries, cached arbiters are kept so contact persistence is not lost.
+func (space *Space) UseSpatialIndex(static, dynamic SpatialIndexConstructor) {
+	assert(dynamic != nil, "A dynamic spatial index constructor is required")
+	assert(space.locked == 0, "You cannot switch spatial indexes while the space is locked. Wait until the current query or step is complete.")
+
+	oldStaticShapes := space.staticShapes
+	oldDynamicShapes := space.dynamicShapes
+
+	staticShapes := oldStaticShapes
+	if static != nil {
+		staticShapes = static(ShapeGetBB, nil)
+	}
+	dynamicShapes := dynamic(ShapeGetBB, staticShapes)
+	if tree := dynamicShapes.GetTree(); tree != nil && tree.velocityFunc == nil {
+		tree.velocityFunc = ShapeVelocityFunc
+	}
+
+	oldDynamicShapes.class.Each(func(shape *Shape) {
 		dynamicShapes.class.Insert(shape, shape.hashid)
 	})
 
+	// Static shapes are inserted after the dynamic ones so that pairs cached by
+	// the static index are rebuilt against the new dynamic index.
+	var shapes []*Shape
+	oldStaticShapes.class.Each(func(shape *Shape) {
+		shapes = append(shapes, shape)
+	})
+	for _, shape := range shapes {
+		if static == nil {
+			staticShapes.class.Remove(shape, shape.hashid)
+		}
+		staticShapes.class.Insert(shape, shape.hashid)
+	}
+
 	space.staticShapes = staticShapes
 	space.dynamicShapes = dynamicShapes
 }
@@ -961,6 +1083,118 @@ func NearestPointQueryNearest(obj any, shape *Shape, collisionId uint32, out any
 	return collisionId
 }
 
+// PointQueryKNearest returns up to k shapes closest to point and within maxDistance, sorted by distance.
+// Sensor shapes are ignored like in PointQueryNearest.
+//
+// BBTree indexes are searched best-first, so only the parts of the tree that can hold one of the k nearest shapes are visited.
+func (space *Space) PointQueryKNearest(point Vector, k int, maxDistance float64, filter ShapeFilter) []PointQueryInfo {
+	if k <= 0 {
+		return nil
+	}
+
+	context := &kNearestContext{point: point, k: k, maxDistance: maxDistance, filter: filter}
+	queue := &nodeQueue{}
+
+	for _, index := range []*SpatialIndex{space.dynamicShapes, space.staticShapes} {
+		if root := index.GetRootIfTree(); root != nil {
+			heap.Push(queue, nodeQueueItem{root, pointBBDistanceBound(point, root.bb)})
+		} else if index.GetTree() == nil {
+			bb := NewBBForCircle(point, math.Max(maxDistance, 0))
+			index.class.Query(context, bb, func(_ any, shape *Shape, collisionId uint32, _ any) uint32 {
+				context.add(shape)
+				return collisionId
+			}, nil)
+		}
+	}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(nodeQueueItem)
+		if item.bound >= context.worst() {
+			// Everything left in the queue is at least this far away.
+			break
+		}
+
+		node := item.node
+		if node.IsLeaf() {
+			context.add(node.obj)
+			continue
+		}
+		for _, child := range []*Node{node.a, node.b} {
+			if bound := pointBBDistanceBound(point, child.bb); bound < context.worst() {
+				heap.Push(queue, nodeQueueItem{child, bound})
+			}
+		}
+	}
+
+	return context.results
+}
+
+type kNearestContext struct {
+	point       Vector
+	k           int
+	maxDistance float64
+	filter      ShapeFilter
+
+	// sorted by distance, at most k long
+	results []PointQueryInfo
+}
+
+// worst returns the distance a shape must beat to be one of the k nearest found so far.
+func (context *kNearestContext) worst() float64 {
+	if len(context.results) < context.k {
+		return context.maxDistance
+	}
+	return context.results[context.k-1].Distance
+}
+
+func (context *kNearestContext) add(shape *Shape) {
+	if shape.Filter.Reject(context.filter) || shape.sensor {
+		return
+	}
+
+	info := shape.PointQuery(context.point)
+	if info.Distance >= context.worst() {
+		return
+	}
+
+	i, _ := slices.BinarySearchFunc(context.results, info.Distance, func(e PointQueryInfo, d float64) int {
+		return compareFloat(e.Distance, d)
+	})
+	context.results = slices.Insert(context.results, i, info)
+	if len(context.results) > context.k {
+		context.results = context.results[:context.k]
+	}
+}
+
+// pointBBDistanceBound returns a lower bound of the signed distance from p to any shape inside bb.
+// When p is inside bb a shape can't be deeper than the distance from p to the edge of bb.
+func pointBBDistanceBound(p Vector, bb BB) float64 {
+	if bb.ContainsVect(p) {
+		return -math.Min(math.Min(p.X-bb.L, bb.R-p.X), math.Min(p.Y-bb.B, bb.T-p.Y))
+	}
+	return p.Distance(bb.ClampVect(&p))
+}
+
+type nodeQueueItem struct {
+	node  *Node
+	bound float64
+}
+
+// nodeQueue is a priority queue of BBTree nodes ordered by their distance bound.
+type nodeQueue []nodeQueueItem
+
+func (q nodeQueue) Len() int           { return len(q) }
+func (q nodeQueue) Less(i, j int) bool { return q[i].bound < q[j].bound }
+func (q nodeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x any)        { *q = append(*q, x.(nodeQueueItem)) }
+func (q *nodeQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
 type SpaceBBQueryFunc func(shape *Shape, data any)
 
 type BBQueryContext struct {
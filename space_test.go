@@ -1,6 +1,10 @@
 package cp
 
-import "testing"
+import (
+	"math"
+	"slices"
+	"testing"
+)
 
 func TestSpace_ShapeQuery(t *testing.T) {
 	space := NewSpace()
@@ -42,3 +46,120 @@ func TestSpace_ReindexShape(t *testing.T) {
 		t.Errorf("got [%[1]v:%[1]T] want [%[2]v:%[2]T]", got, want)
 	}
 }
+
+func TestSpace_UseSpatialIndex(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	space.AddShape(NewSegment(space.StaticBody, Vector{-100, 0}, Vector{100, 0}, 0))
+	body := space.AddBody(NewBody(1, MomentForBox(1, 10, 10)))
+	body.SetPosition(Vector{0, 5})
+	space.AddShape(NewBox(body, 10, 10, 0))
+
+	for range 10 {
+		space.Step(1.0 / 60.0)
+	}
+	arbiters := space.cachedArbiters.Count()
+	if arbiters == 0 {
+		t.Fatal("Expected the box to rest on the ground")
+	}
+
+	space.UseSpatialHash(20, 100)
+	if got := space.cachedArbiters.Count(); got != arbiters {
+		t.Errorf("Expected cached arbiters to be kept, got %v want %v", got, arbiters)
+	}
+	for range 10 {
+		space.Step(1.0 / 60.0)
+	}
+
+	space.UseSpatialIndex(NewBBTree, NewBBTree)
+	space.UseSpatialIndex(nil, NewBBTree)
+	for range 10 {
+		space.Step(1.0 / 60.0)
+	}
+	if body.Position().Y < 4 {
+		t.Errorf("Box fell through the ground after switching indexes: %v", body.Position())
+	}
+}
+
+func TestSpace_PointQueryKNearest(t *testing.T) {
+	space := NewSpace()
+	var shapes []*Shape
+	for i := range 50 {
+		body := space.StaticBody
+		if i%2 == 0 {
+			body = space.AddBody(NewBody(1, 1))
+		}
+		pos := Vector{float64(i%10) * 7, float64(i/10) * 9}
+		shapes = append(shapes, space.AddShape(NewCircle(body, 1+float64(i%3), pos)))
+	}
+
+	point := Vector{20, 20}
+	var expected []float64
+	for _, shape := range shapes {
+		if d := shape.PointQuery(point).Distance; d < 30 {
+			expected = append(expected, d)
+		}
+	}
+	slices.Sort(expected)
+
+	results := space.PointQueryKNearest(point, 8, 30, SHAPE_FILTER_ALL)
+	if len(results) != 8 {
+		t.Fatalf("Expected 8 results, got %v", len(results))
+	}
+	for i, info := range results {
+		if info.Distance != expected[i] {
+			t.Errorf("Result %v has distance %v, expected %v", i, info.Distance, expected[i])
+		}
+	}
+	if nearest := space.PointQueryNearest(point, 30, SHAPE_FILTER_ALL); nearest.Shape != results[0].Shape {
+		t.Error("Expected the first result to be the nearest shape")
+	}
+
+	if got := len(space.PointQueryKNearest(point, 100, 30, SHAPE_FILTER_ALL)); got != len(expected) {
+		t.Errorf("Expected %v results within maxDistance, got %v", len(expected), got)
+	}
+}
+
+func TestSpace_GeometryQuery(t *testing.T) {
+	space := NewSpace()
+	box := space.AddShape(NewBox2(space.StaticBody, NewBB(0, 0, 10, 10), 0))
+
+	var found []*Shape
+	capsule := NewCapsuleGeometry(Vector{-5, 5}, Vector{1, 5}, 0.5)
+	space.GeometryQuery(capsule, SHAPE_FILTER_ALL, func(shape *Shape, points *ContactPointSet) {
+		found = append(found, shape)
+		if points.Count == 0 {
+			t.Error("Expected contact points")
+		}
+	})
+	if len(found) != 1 || found[0] != box {
+		t.Errorf("Expected the capsule to only overlap the box, got %v", found)
+	}
+
+	triangle := NewPolyGeometry([]Vector{{0, 0}, {2, 0}, {1, 2}}, NewTransformTranslate(Vector{30, 0}), 0)
+	if space.GeometryQuery(triangle, SHAPE_FILTER_ALL, nil) {
+		t.Error("Expected the triangle not to overlap anything")
+	}
+
+	circle := space.AddShape(NewCircle(space.StaticBody, 1, Vector{20, 5}))
+	casts := []struct {
+		geometry    *QueryGeometry
+		translation Vector
+		hit         *Shape
+	}{
+		{NewCircleGeometry(Vector{-10, 5}, 1), Vector{60, 0}, box},
+		{capsule, Vector{60, 0}, box},
+		{triangle, Vector{-11, 4}, circle},
+		{triangle, Vector{0, 20}, nil},
+	}
+	for i, cast := range casts {
+		info := space.GeometryCastFirst(cast.geometry, cast.translation, SHAPE_FILTER_ALL)
+		if info.Shape != cast.hit {
+			t.Errorf("Cast %v hit %v, expected %v", i, info.Shape, cast.hit)
+			continue
+		}
+		if info.Shape != nil && info.Alpha > 0 && math.Abs(info.Points.Points[0].Distance) > 0.01 {
+			t.Errorf("Expected cast %v to be touching at the hit, distance was %v", i, info.Points.Points[0].Distance)
+		}
+	}
+}
@@ -5,6 +5,9 @@ type SpatialIndexIterator func(obj *Shape)
 type SpatialIndexQuery func(obj1 any, obj2 *Shape, collisionId uint32, data any) uint32
 type SpatialIndexSegmentQuery func(obj1 any, obj2 *Shape, data any) float64
 
+// SpatialIndexConstructor creates a spatial index that collides against staticIndex, such as NewBBTree.
+type SpatialIndexConstructor func(bbfunc SpatialIndexBB, staticIndex *SpatialIndex) *SpatialIndex
+
 // SpatialIndexer implemented by BBTree
 type SpatialIndexer interface {
 	Count() int
@@ -43,18 +46,21 @@ func NewSpatialIndex(klass SpatialIndexer, bbfunc SpatialIndexBB, staticIndex *S
 	return index
 }
 
+// GetTree returns the BBTree backing the index, or nil if the index is not a BBTree.
 func (index *SpatialIndex) GetTree() *BBTree {
 	if index == nil {
 		return nil
 	}
-	return index.class.(*BBTree)
+	tree, _ := index.class.(*BBTree)
+	return tree
 }
 
 func (index *SpatialIndex) GetRootIfTree() *Node {
-	if index == nil {
+	tree := index.GetTree()
+	if tree == nil {
 		return nil
 	}
-	return index.class.(*BBTree).root
+	return tree.root
 }
 
 func (dynamicIndex *SpatialIndex) CollideStatic(staticIndex *SpatialIndex, f SpatialIndexQuery, data any) {
@@ -0,0 +1,78 @@
+package cp
+
+// TargetJoint pulls a point on a body towards a target in world coordinates, like dragging it with the mouse.
+//
+// It is soft by default, behaving like a spring with a frequency of 5Hz and a damping ratio of 0.7 (see SetSoftness),
+// and the force it can apply is limited by the constraint's max force so the body can't be dragged through other bodies.
+// The joint holds its own static body, so B is the only body that needs to be in the space.
+type TargetJoint struct {
+	*Constraint
+
+	// AnchorB is the point being pulled, in b's local coordinates.
+	AnchorB Vector
+
+	target Vector
+
+	r2   Vector
+	k    Mat2x2
+	c    Vector
+	bias Vector
+
+	jAcc Vector
+}
+
+// NewTargetJoint creates a target joint pulling the point of body in world coordinates towards target.
+func NewTargetJoint(body *Body, point, target Vector) *Constraint {
+	joint := &TargetJoint{
+		AnchorB: body.WorldToLocal(point),
+		target:  target,
+	}
+	joint.Constraint = NewConstraint(joint, NewStaticBody(), body)
+	joint.SetSoftness(5, 0.7)
+	return joint.Constraint
+}
+
+// Target returns the point in world coordinates that the body is being pulled towards.
+func (joint *TargetJoint) Target() Vector {
+	return joint.target
+}
+
+// SetTarget moves the target and wakes up the body.
+func (joint *TargetJoint) SetTarget(target Vector) {
+	joint.b.Activate()
+	joint.target = target
+}
+
+func (joint *TargetJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+	joint.k = k_tensor(a, b, Vector{}, joint.r2)
+	joint.c = b.p.Add(joint.r2).Sub(joint.target)
+
+	joint.bias = joint.soft.biasVect(joint.c, joint.maxBias)
+}
+
+func (joint *TargetJoint) ApplyCachedImpulse(dt_coef float64) {
+	apply_impulses(joint.a, joint.b, Vector{}, joint.r2, joint.jAcc.Mult(dt_coef))
+}
+
+func (joint *TargetJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+	vr := relative_velocity(a, b, Vector{}, joint.r2)
+	j := joint.soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
+
+	jOld := joint.jAcc
+	joint.jAcc = jOld.Add(j).Clamp(joint.maxForce * dt)
+	apply_impulses(a, b, Vector{}, joint.r2, joint.jAcc.Sub(jOld))
+}
+
+func (joint *TargetJoint) GetImpulse() float64 {
+	return joint.jAcc.Length()
+}
+
+func (joint *TargetJoint) reactionImpulses() Jacobian {
+	return pointImpulses(Vector{}, joint.r2, joint.jAcc)
+}
@@ -0,0 +1,163 @@
+package cp
+
+import "math"
+
+// VehicleWheel describes one wheel of a vehicle.
+type VehicleWheel struct {
+	// Position is where the center of the wheel is attached to the chassis, in the chassis' local coordinates.
+	Position     Vector
+	Radius, Mass float64
+
+	// Frequency (in Hz) and DampingRatio of the suspension, which travels along the chassis' y axis.
+	// They default to 4Hz and 0.7.
+	Frequency, DampingRatio float64
+
+	// Driven wheels are turned by the engine. All of the wheels have brakes.
+	Driven bool
+}
+
+// VehicleOptions describes a vehicle created by NewVehicle.
+type VehicleOptions struct {
+	// Position and Angle place the center of the chassis in the world.
+	Position Vector
+	Angle    float64
+
+	// The chassis is a box of ChassisWidth by ChassisHeight, which faces forwards along its x axis.
+	ChassisWidth, ChassisHeight, ChassisMass float64
+	Wheels                                   []VehicleWheel
+
+	// MaxTorque is the torque the engine applies to each driven wheel at full throttle.
+	MaxTorque float64
+	// TorqueCurve scales MaxTorque by how fast the driven wheel is turning, from 0 when it's still to 1 at MaxSpeed.
+	// A nil TorqueCurve gives full torque at any speed.
+	TorqueCurve func(speed float64) float64
+	// MaxSpeed is how fast the engine can drive the rim of the wheels, in distance per second.
+	MaxSpeed float64
+	// BrakeTorque is the torque each wheel's brake applies to stop it turning when fully applied.
+	BrakeTorque float64
+
+	// Filter is used for all of the vehicle's shapes, with the defaults described on ShapeFilter.
+	Filter *ShapeFilter
+}
+
+// Vehicle is a chassis with wheels on WheelJoints, controlled with a throttle and brake.
+// The vehicle updates the wheels' motors from the PreSolve callbacks of their joints.
+type Vehicle struct {
+	Chassis      *Body
+	ChassisShape *Shape
+	// Wheels, WheelShapes and Joints are indexed like the Wheels in the options.
+	Wheels      []*Body
+	WheelShapes []*Shape
+	Joints      []*Constraint
+
+	space           *Space
+	opts            VehicleOptions
+	throttle, brake float64
+}
+
+// NewVehicle creates a vehicle in space. The wheels start at their attachment points with their tires having a friction of 1.
+func NewVehicle(space *Space, opts VehicleOptions) *Vehicle {
+	assert(opts.ChassisMass > 0, "The chassis must have mass")
+
+	vehicle := &Vehicle{
+		space: space,
+		opts:  opts,
+	}
+
+	filter := compositeFilter(opts.Filter)
+
+	chassis := space.AddBody(NewBody(opts.ChassisMass, MomentForBox(opts.ChassisMass, opts.ChassisWidth, opts.ChassisHeight)))
+	chassis.SetAngle(opts.Angle)
+	chassis.SetPosition(opts.Position)
+	vehicle.Chassis = chassis
+	vehicle.ChassisShape = space.AddShape(NewBox(chassis, opts.ChassisWidth, opts.ChassisHeight, 0))
+	vehicle.ChassisShape.SetFilter(filter)
+
+	for _, options := range opts.Wheels {
+		assert(options.Radius > 0 && options.Mass > 0, "Wheels must have a size and mass")
+
+		wheel := space.AddBody(NewBody(options.Mass, MomentForCircle(options.Mass, 0, options.Radius, Vector{})))
+		wheel.SetAngle(opts.Angle)
+		wheel.SetPosition(chassis.LocalToWorld(options.Position))
+		shape := space.AddShape(NewCircle(wheel, options.Radius, Vector{}))
+		shape.SetFriction(1)
+		shape.SetFilter(filter)
+
+		constraint := space.AddConstraint(NewWheelJoint(chassis, wheel, options.Position, Vector{}, Vector{0, 1}))
+		joint := constraint.Class.(*WheelJoint)
+		joint.Frequency, joint.DampingRatio = options.Frequency, options.DampingRatio
+		if joint.Frequency == 0 {
+			joint.Frequency, joint.DampingRatio = 4, 0.7
+		}
+		constraint.PreSolve = func(constraint *Constraint, space *Space) {
+			vehicle.updateWheel(constraint.Class.(*WheelJoint), options)
+		}
+
+		vehicle.Wheels = append(vehicle.Wheels, wheel)
+		vehicle.WheelShapes = append(vehicle.WheelShapes, shape)
+		vehicle.Joints = append(vehicle.Joints, constraint)
+	}
+
+	return vehicle
+}
+
+func (vehicle *Vehicle) Throttle() float64 {
+	return vehicle.throttle
+}
+
+// SetThrottle sets how hard the engine drives the vehicle, from -1 for full reverse to 1 for full speed ahead.
+func (vehicle *Vehicle) SetThrottle(throttle float64) {
+	vehicle.throttle = Clamp(throttle, -1, 1)
+	vehicle.Chassis.Activate()
+}
+
+func (vehicle *Vehicle) Brake() float64 {
+	return vehicle.brake
+}
+
+// SetBrake sets how hard the brakes are applied, from 0 to 1. Braking overrides the throttle.
+func (vehicle *Vehicle) SetBrake(brake float64) {
+	vehicle.brake = Clamp01(brake)
+	vehicle.Chassis.Activate()
+}
+
+// Speed returns how fast the chassis is moving forwards.
+func (vehicle *Vehicle) Speed() float64 {
+	return vehicle.Chassis.Velocity().Dot(vehicle.Chassis.Rotation())
+}
+
+func (vehicle *Vehicle) updateWheel(joint *WheelJoint, wheel VehicleWheel) {
+	opts := vehicle.opts
+
+	switch {
+	case vehicle.brake > 0:
+		joint.EnableMotor = true
+		joint.MotorSpeed = 0
+		joint.MaxMotorTorque = vehicle.brake * opts.BrakeTorque
+	case wheel.Driven && vehicle.throttle != 0:
+		// the wheels turn clockwise to drive forwards
+		maxSpeed := opts.MaxSpeed / wheel.Radius
+		torque := opts.MaxTorque
+		if opts.TorqueCurve != nil {
+			speed := math.Abs(joint.b.w-joint.a.w) / maxSpeed
+			torque *= opts.TorqueCurve(Clamp01(speed))
+		}
+
+		joint.EnableMotor = true
+		joint.MotorSpeed = -math.Copysign(maxSpeed, vehicle.throttle)
+		joint.MaxMotorTorque = math.Abs(vehicle.throttle) * torque
+	default:
+		joint.EnableMotor = false
+	}
+}
+
+// Remove removes the vehicle's joints, shapes and bodies from the space.
+func (vehicle *Vehicle) Remove() {
+	for i, wheel := range vehicle.Wheels {
+		vehicle.space.RemoveConstraint(vehicle.Joints[i])
+		vehicle.space.RemoveShape(vehicle.WheelShapes[i])
+		vehicle.space.RemoveBody(wheel)
+	}
+	vehicle.space.RemoveShape(vehicle.ChassisShape)
+	vehicle.space.RemoveBody(vehicle.Chassis)
+}
@@ -0,0 +1,63 @@
+package cp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVehicle(t *testing.T) {
+	space := NewSpace()
+	space.SetGravity(Vector{0, -100})
+	space.AddShape(NewSegment(space.StaticBody, Vector{-1000, 0}, Vector{1000, 0}, 0)).SetFriction(1)
+
+	wheel := VehicleWheel{Radius: 1, Mass: 1, Frequency: 5, DampingRatio: 1}
+	front, back := wheel, wheel
+	front.Position, back.Position = Vector{2, -1}, Vector{-2, -1}
+	back.Driven = true
+
+	vehicle := NewVehicle(space, VehicleOptions{
+		Position:      Vector{0, 2.5},
+		ChassisWidth:  6,
+		ChassisHeight: 1,
+		ChassisMass:   5,
+		Wheels:        []VehicleWheel{front, back},
+		MaxTorque:     500,
+		TorqueCurve: func(speed float64) float64 {
+			return 1 - speed/2
+		},
+		MaxSpeed:    20,
+		BrakeTorque: 1000,
+	})
+
+	stepSpace(space, 60)
+	if math.Abs(vehicle.Speed()) > 0.01 {
+		t.Fatalf("vehicle is rolling away at %v", vehicle.Speed())
+	}
+
+	vehicle.SetThrottle(1)
+	stepSpace(space, 300)
+	if speed := vehicle.Speed(); math.Abs(speed-20) > 0.5 {
+		t.Errorf("vehicle drove at %v, want its max speed of 20", speed)
+	}
+	if w := vehicle.Wheels[0].AngularVelocity(); math.Abs(w+20) > 0.5 {
+		t.Errorf("front wheel rolling at %v", w)
+	}
+
+	vehicle.SetBrake(1)
+	stepSpace(space, 120)
+	if speed := vehicle.Speed(); math.Abs(speed) > 0.01 {
+		t.Errorf("vehicle still moving at %v after braking", speed)
+	}
+
+	vehicle.SetBrake(0)
+	vehicle.SetThrottle(-1)
+	stepSpace(space, 60)
+	if vehicle.Speed() >= 0 {
+		t.Errorf("vehicle didn't reverse")
+	}
+
+	vehicle.Remove()
+	if len(space.dynamicBodies) != 0 || len(space.constraints) != 0 {
+		t.Errorf("vehicle wasn't removed from the space")
+	}
+}
@@ -0,0 +1,182 @@
+package cp
+
+import "math"
+
+// WeldJoint locks the relative position and angle of two bodies.
+//
+// The joint is rigid by default. Use SetSoftness to make it behave like a stiff spring instead.
+type WeldJoint struct {
+	*Constraint
+
+	AnchorA, AnchorB Vector
+	ReferenceAngle   float64
+
+	r1, r2 Vector
+	k      Mat2x2
+	iSum   float64
+
+	bias       Vector
+	angleBias  float64
+	jAcc       Vector
+	jAngleAcc  float64
+	k33        [9]float64
+	angleError float64
+}
+
+// NewWeldJoint welds a and b together at a pivot in world coordinates, keeping their current relative angle.
+func NewWeldJoint(a, b *Body, pivot Vector) *Constraint {
+	return NewWeldJoint2(a, b, a.WorldToLocal(pivot), b.WorldToLocal(pivot), b.a-a.a)
+}
+
+// NewWeldJoint2 welds a and b together with anchors in body local coordinates and a reference angle of b relative to a.
+func NewWeldJoint2(a, b *Body, anchorA, anchorB Vector, referenceAngle float64) *Constraint {
+	joint := &WeldJoint{
+		AnchorA:        anchorA,
+		AnchorB:        anchorB,
+		ReferenceAngle: referenceAngle,
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *WeldJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r1 = a.transform.Vect(joint.AnchorA.Sub(a.cog))
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+	joint.iSum = a.i_inv + b.i_inv
+	if fixedRotation(a, b) {
+		joint.iSum = 0
+	}
+
+	delta := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
+	joint.angleError = b.a - a.a - joint.ReferenceAngle
+	joint.bias = joint.soft.biasVect(delta, joint.maxBias)
+	joint.angleBias = joint.soft.bias(joint.angleError, joint.maxBias)
+
+	if joint.solveSeparately() {
+		// Soft welds solve the point and the angle separately so each can be scaled by the softness.
+		// So do welds between bodies with fixed rotation, which only have the point to solve.
+		joint.k = k_tensor(a, b, joint.r1, joint.r2)
+	} else {
+		// Rigid welds solve the point and angle together to avoid the drift of solving them one after another.
+		joint.k33 = weldMass(a, b, joint.r1, joint.r2)
+	}
+}
+
+// solveSeparately reports whether the point and angle are solved one after another instead of together.
+// The 3x3 effective mass can't be inverted when both bodies have fixed rotation.
+func (joint *WeldJoint) solveSeparately() bool {
+	return joint.frequency > 0 || joint.iSum == 0
+}
+
+// weldMass returns the inverse of the 3x3 effective mass matrix of the point and angle constraints.
+func weldMass(a, b *Body, r1, r2 Vector) [9]float64 {
+	mSum := a.m_inv + b.m_inv
+	iA := a.i_inv
+	iB := b.i_inv
+
+	k11 := mSum + r1.Y*r1.Y*iA + r2.Y*r2.Y*iB
+	k12 := -r1.Y*r1.X*iA - r2.Y*r2.X*iB
+	k13 := -r1.Y*iA - r2.Y*iB
+	k22 := mSum + r1.X*r1.X*iA + r2.X*r2.X*iB
+	k23 := r1.X*iA + r2.X*iB
+	k33 := iA + iB
+
+	return invertSymmetric33(k11, k12, k13, k22, k23, k33)
+}
+
+func invertSymmetric33(k11, k12, k13, k22, k23, k33 float64) [9]float64 {
+	c11 := k22*k33 - k23*k23
+	c12 := k13*k23 - k12*k33
+	c13 := k12*k23 - k13*k22
+	det := k11*c11 + k12*c12 + k13*c13
+	assert(det != 0, "Unsolvable constraint")
+	det_inv := 1 / det
+
+	c22 := k11*k33 - k13*k13
+	c23 := k13*k12 - k11*k23
+	c33 := k11*k22 - k12*k12
+	return [9]float64{
+		c11 * det_inv, c12 * det_inv, c13 * det_inv,
+		c12 * det_inv, c22 * det_inv, c23 * det_inv,
+		c13 * det_inv, c23 * det_inv, c33 * det_inv,
+	}
+}
+
+func (joint *WeldJoint) ApplyCachedImpulse(dt_coef float64) {
+	a := joint.a
+	b := joint.b
+
+	apply_impulses(a, b, joint.r1, joint.r2, joint.jAcc.Mult(dt_coef))
+	j := joint.jAngleAcc * dt_coef
+	a.w -= j * a.i_inv
+	b.w += j * b.i_inv
+}
+
+func (joint *WeldJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	r1 := joint.r1
+	r2 := joint.r2
+	jMax := joint.maxForce * dt
+
+	if joint.solveSeparately() {
+		soft := joint.soft
+
+		wr := b.w - a.w
+		jAngle := 0.0
+		if joint.iSum != 0 {
+			jAngle = soft.impulse(1/joint.iSum, joint.angleBias-wr, joint.jAngleAcc)
+		}
+		jAngleOld := joint.jAngleAcc
+		joint.jAngleAcc = Clamp(jAngleOld+jAngle, -jMax, jMax)
+		jAngle = joint.jAngleAcc - jAngleOld
+		a.w -= jAngle * a.i_inv
+		b.w += jAngle * b.i_inv
+
+		vr := relative_velocity(a, b, r1, r2)
+		j := soft.impulseVect(&joint.k, joint.bias.Sub(vr), joint.jAcc)
+		jOld := joint.jAcc
+		joint.jAcc = jOld.Add(j).Clamp(jMax)
+		apply_impulses(a, b, r1, r2, joint.jAcc.Sub(jOld))
+		return
+	}
+
+	vr := joint.bias.Sub(relative_velocity(a, b, r1, r2))
+	wr := joint.angleBias - (b.w - a.w)
+
+	k := &joint.k33
+	j := Vector{
+		k[0]*vr.X + k[1]*vr.Y + k[2]*wr,
+		k[3]*vr.X + k[4]*vr.Y + k[5]*wr,
+	}
+	jAngle := k[6]*vr.X + k[7]*vr.Y + k[8]*wr
+
+	jOld := joint.jAcc
+	joint.jAcc = jOld.Add(j).Clamp(jMax)
+	j = joint.jAcc.Sub(jOld)
+
+	jAngleOld := joint.jAngleAcc
+	joint.jAngleAcc = Clamp(jAngleOld+jAngle, -jMax, jMax)
+	jAngle = joint.jAngleAcc - jAngleOld
+
+	apply_impulses(a, b, r1, r2, j)
+	a.w -= jAngle * a.i_inv
+	b.w += jAngle * b.i_inv
+}
+
+func (joint *WeldJoint) GetImpulse() float64 {
+	return math.Sqrt(joint.jAcc.LengthSq() + joint.jAngleAcc*joint.jAngleAcc)
+}
+
+// AngleError returns how far the relative angle was from the reference angle at the start of the last step.
+func (joint *WeldJoint) AngleError() float64 {
+	return joint.angleError
+}
+
+func (joint *WeldJoint) reactionImpulses() Jacobian {
+	return pointImpulses(joint.r1, joint.r2, joint.jAcc).add(angularImpulses(joint.jAngleAcc))
+}
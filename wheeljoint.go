@@ -0,0 +1,187 @@
+package cp
+
+import "math"
+
+// WheelJoint keeps the anchor of b on a line through the anchor of a, letting b rotate freely.
+//
+// The line follows Axis, which is in a's local coordinates. Movement along the axis is the suspension:
+// it is free unless Frequency (in Hz) is set, which makes it a spring with the given DampingRatio.
+// Enabling the motor drives the relative angular velocity of b towards MotorSpeed.
+type WheelJoint struct {
+	*Constraint
+
+	AnchorA, AnchorB Vector
+	Axis             Vector
+
+	Frequency, DampingRatio float64
+
+	EnableMotor    bool
+	MotorSpeed     float64
+	MaxMotorTorque float64
+
+	r1, r2 Vector
+	// suspension axis and the line's normal in world coordinates
+	ax, ay Vector
+	// angular parts of the jacobians along the axis and the normal
+	sAx, sBx, sAy, sBy float64
+
+	lineMass, springMass, motorMass float64
+	bias, springC                   float64
+	springSoft                      softness
+
+	jAcc, springJAcc, motorJAcc float64
+}
+
+// NewWheelJoint creates a wheel joint with anchors in body local coordinates and an axis in a's local coordinates.
+func NewWheelJoint(a, b *Body, anchorA, anchorB, axis Vector) *Constraint {
+	joint := &WheelJoint{
+		AnchorA: anchorA,
+		AnchorB: anchorB,
+		Axis:    axis.Normalize(),
+	}
+	joint.Constraint = NewConstraint(joint, a, b)
+	return joint.Constraint
+}
+
+func (joint *WheelJoint) PreStep(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	joint.r1 = a.transform.Vect(joint.AnchorA.Sub(a.cog))
+	joint.r2 = b.transform.Vect(joint.AnchorB.Sub(b.cog))
+	d := b.p.Add(joint.r2).Sub(a.p.Add(joint.r1))
+
+	mSum := a.m_inv + b.m_inv
+
+	// point to line constraint
+	joint.ay = a.transform.Vect(joint.Axis).Perp()
+	joint.sAy = d.Add(joint.r1).Cross(joint.ay)
+	joint.sBy = joint.r2.Cross(joint.ay)
+	joint.lineMass = 1 / (mSum + a.i_inv*joint.sAy*joint.sAy + b.i_inv*joint.sBy*joint.sBy)
+
+	joint.bias = joint.soft.bias(d.Dot(joint.ay), joint.maxBias)
+
+	// suspension spring
+	joint.ax = a.transform.Vect(joint.Axis)
+	joint.sAx = d.Add(joint.r1).Cross(joint.ax)
+	joint.sBx = joint.r2.Cross(joint.ax)
+	joint.springMass = 0
+	if k := mSum + a.i_inv*joint.sAx*joint.sAx + b.i_inv*joint.sBx*joint.sBx; k > 0 {
+		joint.springMass = 1 / k
+	}
+	// the spring is at rest when the anchors are on top of each other
+	joint.springC = d.Dot(joint.ax)
+	joint.springSoft = newSoftness(joint.Frequency, joint.DampingRatio, dt)
+	if joint.Frequency <= 0 {
+		joint.springJAcc = 0
+	}
+
+	// motor
+	joint.motorMass = 0
+	if iSum := a.i_inv + b.i_inv; iSum > 0 {
+		joint.motorMass = 1 / iSum
+	}
+	if !joint.EnableMotor {
+		joint.motorJAcc = 0
+	}
+}
+
+// applyAxisImpulse applies an impulse along an axis with the given angular jacobian parts.
+func applyAxisImpulse(a, b *Body, axis Vector, sA, sB, j float64) {
+	p := axis.Mult(j)
+	a.v = a.v.Sub(p.Mult(a.m_inv))
+	a.w -= j * sA * a.i_inv
+	b.v = b.v.Add(p.Mult(b.m_inv))
+	b.w += j * sB * b.i_inv
+}
+
+// axisImpulses returns the impulses applied by applyAxisImpulse.
+func axisImpulses(axis Vector, sA, sB, j float64) Jacobian {
+	return Jacobian{axis.Mult(-j), -sA * j, axis.Mult(j), sB * j}
+}
+
+// axisVelocity returns the relative velocity along an axis with the given angular jacobian parts.
+func axisVelocity(a, b *Body, axis Vector, sA, sB float64) float64 {
+	return axis.Dot(b.v.Sub(a.v)) + sB*b.w - sA*a.w
+}
+
+func (joint *WheelJoint) ApplyCachedImpulse(dt_coef float64) {
+	a := joint.a
+	b := joint.b
+
+	applyAxisImpulse(a, b, joint.ay, joint.sAy, joint.sBy, joint.jAcc*dt_coef)
+	applyAxisImpulse(a, b, joint.ax, joint.sAx, joint.sBx, joint.springJAcc*dt_coef)
+
+	j := joint.motorJAcc * dt_coef
+	a.w -= j * a.i_inv
+	b.w += j * b.i_inv
+}
+
+func (joint *WheelJoint) ApplyImpulse(dt float64) {
+	a := joint.a
+	b := joint.b
+
+	if joint.EnableMotor {
+		jMax := joint.MaxMotorTorque * dt
+		j := (joint.MotorSpeed - (b.w - a.w)) * joint.motorMass
+		jOld := joint.motorJAcc
+		joint.motorJAcc = Clamp(jOld+j, -jMax, jMax)
+		j = joint.motorJAcc - jOld
+
+		a.w -= j * a.i_inv
+		b.w += j * b.i_inv
+	}
+
+	if joint.Frequency > 0 {
+		soft := joint.springSoft
+		vr := axisVelocity(a, b, joint.ax, joint.sAx, joint.sBx)
+		j := soft.impulse(joint.springMass, -soft.biasRate*joint.springC-vr, joint.springJAcc)
+		joint.springJAcc += j
+		applyAxisImpulse(a, b, joint.ax, joint.sAx, joint.sBx, j)
+	}
+
+	vr := axisVelocity(a, b, joint.ay, joint.sAy, joint.sBy)
+	j := joint.soft.impulse(joint.lineMass, joint.bias-vr, joint.jAcc)
+	jMax := joint.maxForce * dt
+	jOld := joint.jAcc
+	joint.jAcc = Clamp(jOld+j, -jMax, jMax)
+	applyAxisImpulse(a, b, joint.ay, joint.sAy, joint.sBy, joint.jAcc-jOld)
+}
+
+func (joint *WheelJoint) GetImpulse() float64 {
+	return math.Sqrt(joint.jAcc*joint.jAcc + joint.springJAcc*joint.springJAcc + joint.motorJAcc*joint.motorJAcc)
+}
+
+// Translation returns how far the anchor of b is along the axis from the anchor of a.
+func (joint *WheelJoint) Translation() float64 {
+	return axisTranslation(joint.a, joint.b, joint.AnchorA, joint.AnchorB, joint.Axis)
+}
+
+// Speed returns how fast the suspension is extending along the axis.
+func (joint *WheelJoint) Speed() float64 {
+	return axisSpeed(joint.a, joint.b, joint.AnchorA, joint.AnchorB, joint.Axis)
+}
+
+// axisTranslation returns the distance between two anchors along an axis in a's local coordinates.
+func axisTranslation(a, b *Body, anchorA, anchorB, axis Vector) float64 {
+	d := b.LocalToWorld(anchorB).Sub(a.LocalToWorld(anchorA))
+	return d.Dot(a.transform.Vect(axis))
+}
+
+// axisSpeed returns the rate of change of axisTranslation.
+func axisSpeed(a, b *Body, anchorA, anchorB, axis Vector) float64 {
+	pA := a.LocalToWorld(anchorA)
+	pB := b.LocalToWorld(anchorB)
+	axis = a.transform.Vect(axis)
+
+	vA := a.VelocityAtWorldPoint(pA)
+	vB := b.VelocityAtWorldPoint(pB)
+	// the axis rotates with a
+	return pB.Sub(pA).Dot(axis.Perp().Mult(a.w)) + axis.Dot(vB.Sub(vA))
+}
+
+func (joint *WheelJoint) reactionImpulses() Jacobian {
+	return axisImpulses(joint.ay, joint.sAy, joint.sBy, joint.jAcc).
+		add(axisImpulses(joint.ax, joint.sAx, joint.sBx, joint.springJAcc)).
+		add(angularImpulses(joint.motorJAcc))
+}